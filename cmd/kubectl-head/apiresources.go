@@ -0,0 +1,57 @@
+package main
+
+import (
+	"github.com/seans3/head/pkg/head"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// NewCmdAPIResources creates the "api-resources" command, which lists the
+// first N resource types served by the cluster.
+func NewCmdAPIResources(streams genericclioptions.IOStreams) *cobra.Command {
+	o := head.NewHeadOptions(streams)
+	var namespaced bool
+
+	cmd := &cobra.Command{
+		Use:   "api-resources",
+		Short: "Print the first N API resources supported by the server",
+		Long: `Print the first N API resources supported by the server, sorted by group and name.
+Resources are read from the cached discovery information, which is fetched for all groups in a
+single aggregated discovery request on servers that support it. Filter by group, verbs and scope
+to find the type to head at on clusters with many custom resources.`,
+		Example: `
+  # Print the first 10 resource types
+  kubectl head api-resources
+
+  # Print the cluster-scoped resource types in the example.com group
+  kubectl head api-resources --api-group example.com --namespaced=false
+
+  # Print the names of the first 50 resource types that can be listed
+  kubectl head api-resources --verbs list --limit 50 -o name
+`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if c.Flags().Changed("namespaced") {
+				o.Namespaced = &namespaced
+			}
+			if err := o.Complete(""); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.RunAPIResources()
+		},
+	}
+
+	cmd.Flags().Int64Var(&o.Limit, "limit", head.DefaultHeadLimit, "Number of resource types to print.")
+	cmd.Flags().StringVar(&o.APIGroup, "api-group", "", "Limit to resources in the specified API group.")
+	cmd.Flags().StringSliceVar(&o.Verbs, "verbs", nil, "Limit to resources that support the specified verbs.")
+	cmd.Flags().BoolVar(&namespaced, "namespaced", true, "If false, non-namespaced resources will be returned, otherwise returning namespaced resources by default.")
+	cmd.Flags().StringVarP(o.PrintFlags.OutputFormat, "output", "o", "", "Output format. One of: wide, name.")
+	o.ConfigFlags.AddFlags(cmd.Flags())
+	registerFlagCompletions(cmd, o)
+
+	return cmd
+}
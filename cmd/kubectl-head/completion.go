@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/seans3/head/pkg/head"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// NewCmdCompletion creates the "completion" command, which prints the shell
+// completion script for the plugin.
+func NewCmdCompletion(streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion SHELL",
+		Short: "Print the shell completion script",
+		Long: `Print the completion script for bash, zsh, fish or powershell.
+Source the output in your shell's configuration to enable tab completion.`,
+		Example: `
+  # Load completions for kubectl-head in the current bash session
+  source <(kubectl-head completion bash)
+`,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(c *cobra.Command, args []string) error {
+			// The script completes the plugin binary, which is named
+			// kubectl-head on the PATH rather than after the root command.
+			root := c.Root()
+			root.Use = "kubectl-head"
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(streams.Out, true)
+			case "zsh":
+				return root.GenZshCompletion(streams.Out)
+			case "fish":
+				return root.GenFishCompletion(streams.Out, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(streams.Out)
+			}
+			return fmt.Errorf("unsupported shell %q", args[0])
+		},
+	}
+	return cmd
+}
+
+// registerCompletions registers dynamic completion of the resource type
+// argument, and of the namespace and context flags, for a command that takes
+// a single resource type. It must be called after the flags are added.
+func registerCompletions(cmd *cobra.Command, o *head.HeadOptions) {
+	cmd.ValidArgsFunction = func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return o.CompleteResourceTypes(toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+	registerFlagCompletions(cmd, o)
+}
+
+// registerFlagCompletions registers completion of namespaces and contexts for
+// the flags of a command that has them.
+func registerFlagCompletions(cmd *cobra.Command, o *head.HeadOptions) {
+	completeNamespaces := func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return o.CompleteNames("namespaces", toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+	completeContexts := func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return o.CompleteContexts(toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+
+	for flag, fn := range map[string]cobra.CompletionFunc{
+		"namespace": completeNamespaces,
+		"context":   completeContexts,
+		"contexts":  completeContexts,
+	} {
+		if cmd.Flags().Lookup(flag) != nil {
+			cobra.CheckErr(cmd.RegisterFlagCompletionFunc(flag, fn))
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/seans3/head/pkg/head"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// NewCmdCount creates the "count" command, which prints the number of objects
+// of a resource type without fetching them.
+func NewCmdCount(streams genericclioptions.IOStreams) *cobra.Command {
+	o := head.NewHeadOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "count [type]",
+		Short: "Count the objects of a resource type",
+		Long: `Count the objects of a resource type. Without a label selector, the count is taken from
+the API server's remaining item count in a single one-item request. With a label selector,
+the list is paged through without fetching the objects.`,
+		Example: `
+  # Count the pods in the cluster
+  kubectl head count pods -A
+
+  # Count the pods labeled app=web in the current namespace
+  kubectl head count pods -l app=web
+`,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			resource, err := resourceTypeArg(args, "count")
+			if err != nil {
+				return err
+			}
+			if err := o.Complete(resource); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.RunCount()
+		},
+	}
+
+	cmd.Flags().Int64Var(&o.Limit, "limit", head.DefaultExportLimit, "Number of items to request per page when a label selector requires paging through the list.")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", "", "Selector (label query) to filter on. Supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", false, "If present, count the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	o.ConfigFlags.AddFlags(cmd.Flags())
+	registerCompletions(cmd, o)
+
+	return cmd
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/seans3/head/pkg/head"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// NewCmdExport creates the "export" command, which streams every page of a
+// resource list. It is equivalent to "head --all" with a larger page size.
+func NewCmdExport(streams genericclioptions.IOStreams) *cobra.Command {
+	o := head.NewHeadOptions(streams)
+	o.All = true
+
+	cmd := &cobra.Command{
+		Use:   "export [type]",
+		Short: "Stream every object of a resource type with bounded memory",
+		Long: `Page through an entire resource list, writing each page to stdout or a file as it arrives,
+so that no more than one page is ever held in memory. Progress is reported on stderr.`,
+		Example: `
+  # Export every pod in the cluster as YAML
+  kubectl head export pods -A -o yaml --output-file pods.yaml
+
+  # Stream every pod as one JSON object per line
+  kubectl head export pods -A -o ndjson | jq -r .metadata.name
+`,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runHead(o, args)
+		},
+	}
+
+	cmd.Flags().Int64Var(&o.Limit, "limit", head.DefaultExportLimit, "Number of items to return per page.")
+	cmd.Flags().StringVar(&o.OutputFile, "output-file", "", "File to write the output to instead of stdout.")
+	addListFlags(cmd, o)
+	registerCompletions(cmd, o)
+
+	return cmd
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/seans3/head/pkg/head"
 	"github.com/spf13/cobra"
@@ -40,39 +41,150 @@ It supports pagination through an interactive mode or by manually passing a cont
 
   # Get the second page of pods, using a token from a previous run
   kubectl head pods --limit 10 --continue "eyJhbGciOi..."
+
+  # Export every pod in the cluster as YAML, 500 at a time
+  kubectl head pods -A --all --limit 500 -o yaml --output-file pods.yaml
+
+  # Stream every pod as one JSON object per line into jq
+  kubectl head pods -A --all --limit 500 -o ndjson | jq -r .metadata.name
+
+  # Export the first 100 nodes, including the wide columns, as CSV
+  kubectl head nodes --limit 100 -o csv --wide > nodes.csv
+
+  # Paste the first 20 pods into a postmortem, linked to a dashboard
+  kubectl head pods --limit 20 -o markdown --link-template 'https://dash.example.com/{{.metadata.namespace}}/{{.metadata.name}}'
+
+  # Time each page request while paging through all pods
+  kubectl head pods -A --all --limit 500 -o name --stats > /dev/null
+
+  # Read the first page of pods from the API server cache instead of etcd
+  kubectl head pods --resource-version 0
+
+  # Head at the first 3 pods in each namespace labeled team=payments
+  kubectl head pods --limit 3 --per-namespace --namespace-selector team=payments
+
+  # Check the last 20 log lines of the first 5 pods labeled app=web
+  kubectl head pods -l app=web --limit 5 --logs=20
+
+  # Head at the first 10 widgets as served in v1beta1, while migrating to v1
+  kubectl head widgets --api-version example.com/v1beta1 -o yaml
+
+  # Show the scale of the first 10 deployments
+  kubectl head deployments --subresource scale
+
+  # Show the most recent event about each of the first 10 deployments
+  kubectl head deployments --events
+
+  # Show the Deployment, StatefulSet or other workload that owns each of the first 10 pods
+  kubectl head pods --show-owner --top-owner
+
+  # Show the CPU and memory usage of the first 10 nodes, like kubectl top
+  kubectl head nodes --with-metrics
+
+  # Label the first 20 pods of a stuck batch job, after confirming the list of names
+  kubectl head pods -l job-name=nightly --limit 20 --label triage=stuck
+
+  # Check which of the first 20 pods labeled app=web would be deleted, without deleting them
+  kubectl head pods -l app=web --limit 20 --delete --dry-run=server
+
+  # Delete the jobs labeled cleanup=true 50 at a time, in at most 10 batches 2s apart
+  kubectl head jobs -n team-a -l cleanup=true --limit 50 --exec 'xargs kubectl delete -n "$KUBECTL_HEAD_NAMESPACE" --context "$KUBECTL_HEAD_CONTEXT"' --max-pages 10 --page-delay 2s
+
+  # Head at the first 5 pods labeled app=web in each of two clusters
+  kubectl head pods -A --limit 5 -l app=web --contexts prod-east,prod-west
 `,
+		// Allow resource types as arguments alongside the subcommands.
+		Args:         cobra.ArbitraryArgs,
 		SilenceUsage: true,
 		RunE: func(c *cobra.Command, args []string) error {
-			if len(args) == 0 {
-				return fmt.Errorf("you must specify the type of resource to head")
-			}
-			if len(args) > 1 {
-				return fmt.Errorf("only one resource type is allowed")
-			}
-			
-			if err := o.Complete(args[0]); err != nil {
-				return err
-			}
-			if err := o.Validate(); err != nil {
-				return err
-			}
-			if err := o.Run(); err != nil {
-				return err
-			}
-			return nil
+			return runHead(o, args)
 		},
 	}
+	// The completion command is added explicitly below.
+	cmd.CompletionOptions.DisableDefaultCmd = true
 
 	// Add our custom flags.
 	cmd.Flags().Int64Var(&o.Limit, "limit", head.DefaultHeadLimit, "Number of items to return per page.")
-	cmd.Flags().StringVar(&o.ContinueToken, "continue", "", "A token used to retrieve the next page of results. If not provided, the first page is returned.")
 	cmd.Flags().BoolVarP(&o.Interactive, "interactive", "i", false, "Enable interactive mode to page through results.")
+	cmd.Flags().BoolVar(&o.All, "all", false, "If present, page through the entire list, streaming each page as it arrives instead of stopping after the first page.")
+	cmd.Flags().StringVar(&o.OutputFile, "output-file", "", "File to write the output of --all to instead of stdout.")
+	cmd.Flags().BoolVar(&o.PerNamespace, "per-namespace", false, "If present, show the first --limit objects from each namespace instead of the first --limit objects overall.")
+	cmd.Flags().StringVar(&o.NamespaceSelector, "namespace-selector", "", "Selector (label query) on namespaces to restrict --per-namespace to (e.g. team=payments).")
+	cmd.Flags().Int64Var(&o.Logs, "logs", 0, "If present, print the last `LINES` of the logs of each container of the pods in each page, fetched concurrently.")
+	cmd.Flags().Lookup("logs").NoOptDefVal = strconv.FormatInt(head.DefaultLogLines, 10)
+	cmd.Flags().BoolVar(&o.LogsFirst, "logs-first", false, "If present, --logs prints the first LINES of each log instead of the last.")
+	cmd.Flags().BoolVar(&o.Delete, "delete", false, "If present, delete the objects in the printed page after confirmation.")
+	cmd.Flags().StringArrayVar(&o.Labels, "label", nil, "Label to set (KEY=VALUE) or remove (KEY-) on the objects in the printed page after confirmation. May be repeated.")
+	cmd.Flags().StringArrayVar(&o.Annotations, "annotate", nil, "Annotation to set (KEY=VALUE) or remove (KEY-) on the objects in the printed page after confirmation. May be repeated.")
+	cmd.Flags().StringVar(&o.DryRun, "dry-run", "none", "Must be \"none\" or \"server\". If server, --delete, --label and --annotate are only submitted as a server-side dry run, without confirmation.")
+	cmd.Flags().BoolVar(&o.Yes, "yes", false, "If present, apply --delete, --label and --annotate without asking for confirmation.")
+	cmd.Flags().StringVar(&o.Exec, "exec", "", "Shell command to run for each page, with the page piped to its stdin as names, or in the format given by -o. The next page is only fetched once the command succeeds. The names carry no namespace or context, so the command gets the namespace listed in $KUBECTL_HEAD_NAMESPACE and the kubeconfig context in $KUBECTL_HEAD_CONTEXT (and $KUBECONFIG with --kubeconfig); -A requires an output format such as -o json. The columns of --events, --show-owner and --with-metrics require a table format such as -o tsv.")
+	cmd.Flags().IntVar(&o.MaxPages, "max-pages", 0, "Maximum number of pages to run --exec for, then print the token to resume from. 0 means no limit.")
+	cmd.Flags().DurationVar(&o.PageDelay, "page-delay", 0, "Time to wait between pages with --exec (e.g. 500ms), to spread the load on the API server.")
+	cmd.Flags().StringSliceVar(&o.Contexts, "contexts", nil, "Comma-separated list of kubeconfig contexts to head at concurrently. Results are shown with a CLUSTER column.")
+	cmd.Flags().BoolVar(&o.AllContexts, "all-contexts", false, "If present, head at every context in the kubeconfig concurrently.")
+	cmd.Flags().IntVar(&o.Concurrency, "concurrency", head.DefaultConcurrency, "Maximum number of requests to make in parallel when fanning out.")
+	addListFlags(cmd, o)
+	registerCompletions(cmd, o)
+
+	cmd.AddCommand(
+		NewCmdToken(streams),
+		NewCmdCount(streams),
+		NewCmdExport(streams),
+		NewCmdAPIResources(streams),
+		NewCmdCompletion(streams),
+		NewCmdVersion(streams),
+	)
+
+	return cmd
+}
+
+// addListFlags adds the flags shared by the commands that list a resource:
+// selection, consistency, output and the standard kubectl flags.
+func addListFlags(cmd *cobra.Command, o *head.HeadOptions) {
+	cmd.Flags().StringVar(&o.ContinueToken, "continue", "", "A token used to retrieve the next page of results. If not provided, the first page is returned.")
 	cmd.Flags().StringVarP(&o.Selector, "selector", "l", "", "Selector (label query) to filter on. Supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().StringVar(&o.ResourceVersion, "resource-version", "", "Resource version to read the first page at. Use 0 for a cached read that avoids a quorum read from etcd; note that the server may ignore --limit for cached reads.")
+	cmd.Flags().StringVar(&o.ResourceVersionMatch, "resource-version-match", "", "How --resource-version is applied. One of: NotOlderThan, Exact.")
 	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", false, "If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().BoolVar(&o.Wide, "wide", false, "If present, include the additional columns shown by -o wide in -o csv, tsv, markdown and html output.")
+	cmd.Flags().StringVar(&o.LinkTemplate, "link-template", "", "Go template for a URL to link each object's name to in -o markdown and html output (e.g. 'https://dash.example.com/{{.metadata.namespace}}/{{.metadata.name}}').")
+	cmd.Flags().StringVar(&o.APIVersion, "api-version", "", "API version (group/version) to list the resource in instead of the version preferred by the server (e.g. example.com/v1beta1).")
+	cmd.Flags().BoolVar(&o.Events, "events", false, "If present, add a column with the most recent event about each object in the page.")
+	cmd.Flags().BoolVar(&o.ShowOwner, "show-owner", false, "If present, add a column with the controller that owns each object in the page, as Kind/Name.")
+	cmd.Flags().BoolVar(&o.TopOwner, "top-owner", false, "If present, --show-owner follows owners up to the top-level owner, e.g. the Deployment of a pod rather than its ReplicaSet.")
+	cmd.Flags().BoolVar(&o.WithMetrics, "with-metrics", false, "If present, add columns with the CPU and memory usage of each pod or node in the page, from metrics.k8s.io.")
+	cmd.Flags().StringVar(&o.Subresource, "subresource", "", "If specified, fetch and print this subresource of each object in the page instead of the object. One of: status, scale.")
+	cmd.Flags().StringVar(&o.Stats, "stats", "", "If present, report latency, bytes, rows and resourceVersion of each page request on stderr. One of: human, json.")
+	cmd.Flags().Lookup("stats").NoOptDefVal = "human"
 
 	// Add standard kubectl flags.
 	o.ConfigFlags.AddFlags(cmd.Flags())
 	o.PrintFlags.AddFlags(cmd)
+}
 
-	return cmd
+// resourceTypeArg returns the single resource type argument of a command.
+func resourceTypeArg(args []string, verb string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("you must specify the type of resource to %s", verb)
+	}
+	if len(args) > 1 {
+		return "", fmt.Errorf("only one resource type is allowed")
+	}
+	return args[0], nil
+}
+
+// runHead completes, validates and runs the options for the resource type in args.
+func runHead(o *head.HeadOptions, args []string) error {
+	resource, err := resourceTypeArg(args, "head")
+	if err != nil {
+		return err
+	}
+	if err := o.Complete(resource); err != nil {
+		return err
+	}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	return o.Run()
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"github.com/seans3/head/pkg/head"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// NewCmdToken creates the "token" command, which groups the commands for
+// working with continue tokens.
+func NewCmdToken(streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Work with continue tokens",
+	}
+	cmd.AddCommand(NewCmdTokenInspect(streams))
+	return cmd
+}
+
+// NewCmdTokenInspect creates the "token inspect" command, which decodes a
+// continue token and optionally checks it against a resource type.
+func NewCmdTokenInspect(streams genericclioptions.IOStreams) *cobra.Command {
+	o := head.NewHeadOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "inspect TOKEN [type]",
+		Short: "Decode a continue token",
+		Long: `Decode a continue token and print the resource version and start key it contains.
+If a resource type is given, the token is also checked against that resource on the server
+and the position of the next page in the list is estimated.`,
+		Example: `
+  # Decode a token from a previous run
+  kubectl head token inspect "eyJ2IjoibWV0YS5rOHMuaW8vdjEi..."
+
+  # Check that a token continues a list of pods and estimate its position
+  kubectl head token inspect "eyJ2IjoibWV0YS5rOHMuaW8vdjEi..." pods
+`,
+		Args:         cobra.RangeArgs(1, 2),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 2 {
+				if err := o.Complete(args[1]); err != nil {
+					return err
+				}
+			}
+			return o.InspectToken(args[0])
+		},
+	}
+
+	o.ConfigFlags.AddFlags(cmd.Flags())
+	cmd.ValidArgsFunction = func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// Only the resource type after the token can be completed.
+		if len(args) != 1 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return o.CompleteResourceTypes(toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+	registerFlagCompletions(cmd, o)
+
+	return cmd
+}
@@ -101,9 +101,9 @@ func TestHeadPods(t *testing.T) {
 		}
 	}
 
-	// 5 pods + header + continue token
-	if len(nonEmptyLines) != 7 {
-		t.Errorf("Expected 7 non-empty lines of output, but got %d", len(nonEmptyLines))
+	// 5 pods + header + resource version + continue token
+	if len(nonEmptyLines) != 8 {
+		t.Errorf("Expected 8 non-empty lines of output, but got %d", len(nonEmptyLines))
 	}
 
 	if !strings.Contains(output, "Continue Token:") {
@@ -0,0 +1,218 @@
+package head
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// dryRunModes are the values accepted by --dry-run.
+var dryRunModes = []string{"none", "server"}
+
+// hasActions reports whether the objects in the printed page are to be
+// deleted, labeled or annotated.
+func (o *HeadOptions) hasActions() bool {
+	return o.Delete || len(o.Labels) > 0 || len(o.Annotations) > 0
+}
+
+// isDryRun reports whether actions are only submitted to the server as a dry
+// run.
+func (o *HeadOptions) isDryRun() bool {
+	return o.DryRun == "server"
+}
+
+// validateActions checks the action flags.
+func (o *HeadOptions) validateActions() error {
+	if o.DryRun != "" && !slices.Contains(dryRunModes, o.DryRun) {
+		return fmt.Errorf("--dry-run must be one of: %s", strings.Join(dryRunModes, ", "))
+	}
+	if !o.hasActions() {
+		if o.isDryRun() || o.Yes {
+			return fmt.Errorf("--dry-run and --yes can only be used with --delete, --label or --annotate")
+		}
+		return nil
+	}
+	if o.Delete && (len(o.Labels) > 0 || len(o.Annotations) > 0) {
+		return fmt.Errorf("--delete cannot be used with --label or --annotate")
+	}
+	if o.All || o.PerNamespace || o.isMultiContext() || o.Subresource != "" {
+		return fmt.Errorf("--delete, --label and --annotate cannot be used with --all, --per-namespace, --contexts, --all-contexts or --subresource")
+	}
+	if o.Concurrency <= 0 {
+		return fmt.Errorf("--concurrency must be a positive number")
+	}
+	if _, err := parseMetadataChanges("--label", o.Labels, validateLabel); err != nil {
+		return err
+	}
+	if _, err := parseMetadataChanges("--annotate", o.Annotations, validateAnnotation); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseMetadataChanges parses KEY=VALUE arguments, which set a key, and KEY-
+// arguments, which remove it, into the map of a JSON merge patch, where a nil
+// value removes the key.
+func parseMetadataChanges(flag string, args []string, validate func(key, value string) []string) (map[string]interface{}, error) {
+	changes := map[string]interface{}{}
+	for _, arg := range args {
+		if key, ok := strings.CutSuffix(arg, "-"); ok && !strings.Contains(arg, "=") {
+			if errs := validate(key, ""); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid %s %q: %s", flag, arg, strings.Join(errs, "; "))
+			}
+			changes[key] = nil
+			continue
+		}
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s %q: must be KEY=VALUE to set a key or KEY- to remove it", flag, arg)
+		}
+		if errs := validate(key, value); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid %s %q: %s", flag, arg, strings.Join(errs, "; "))
+		}
+		changes[key] = value
+	}
+	return changes, nil
+}
+
+func validateLabel(key, value string) []string {
+	return append(validation.IsQualifiedName(key), validation.IsValidLabelValue(value)...)
+}
+
+func validateAnnotation(key, _ string) []string {
+	return validation.IsQualifiedName(key)
+}
+
+// applyActions deletes, labels or annotates the objects in a printed page,
+// after listing them and asking for confirmation on ErrOut unless Yes is set
+// or the actions are a dry run. Objects are changed concurrently, with at most
+// Concurrency requests in flight, and the result for each object is reported
+// like kubectl does, e.g. "pod/web-1 deleted". Deletions are made with a UID
+// precondition, so that an object recreated with the same name since the page
+// was listed is left alone.
+func (o *HeadOptions) applyActions(table *metav1.Table, gvr schema.GroupVersionResource) error {
+	if len(table.Rows) == 0 {
+		return nil
+	}
+	objs := make([]*unstructured.Unstructured, len(table.Rows))
+	for i, row := range table.Rows {
+		obj, err := rowObject(row)
+		if err != nil {
+			return err
+		}
+		objs[i] = obj
+	}
+
+	verb, patch, err := o.actionPatch()
+	if err != nil {
+		return err
+	}
+	if !o.Yes && !o.isDryRun() && !o.confirmAction(verb, objs) {
+		fmt.Fprintln(o.ErrOut, "Aborted.")
+		return nil
+	}
+
+	var dryRun []string
+	suffix := ""
+	if o.isDryRun() {
+		dryRun = []string{metav1.DryRunAll}
+		suffix = " (server dry run)"
+	}
+	errs := make([]error, len(objs))
+	runParallel(len(objs), o.Concurrency, func(i int) {
+		client := o.DynamicClient.Resource(gvr).Namespace(objs[i].GetNamespace())
+		if o.Delete {
+			options := metav1.DeleteOptions{DryRun: dryRun}
+			if uid := objs[i].GetUID(); uid != "" {
+				options.Preconditions = &metav1.Preconditions{UID: &uid}
+			}
+			errs[i] = client.Delete(context.Background(), objs[i].GetName(), options)
+			return
+		}
+		_, errs[i] = client.Patch(context.Background(), objs[i].GetName(), types.MergePatchType, patch, metav1.PatchOptions{DryRun: dryRun})
+	})
+
+	out := o.infoOut()
+	fmt.Fprintln(out)
+	failed := 0
+	for i, obj := range objs {
+		ref := o.objectRef(obj)
+		if errs[i] != nil {
+			fmt.Fprintf(o.ErrOut, "error: %s: %v\n", ref, errs[i])
+			failed++
+			continue
+		}
+		fmt.Fprintf(out, "%s %s%s\n", ref, verb, suffix)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d objects could not be %s", failed, len(objs), verb)
+	}
+	return nil
+}
+
+// actionPatch returns the past tense of the action, as reported for each
+// object, and for labels and annotations the merge patch that applies them.
+func (o *HeadOptions) actionPatch() (string, []byte, error) {
+	if o.Delete {
+		return "deleted", nil, nil
+	}
+	labels, err := parseMetadataChanges("--label", o.Labels, validateLabel)
+	if err != nil {
+		return "", nil, err
+	}
+	annotations, err := parseMetadataChanges("--annotate", o.Annotations, validateAnnotation)
+	if err != nil {
+		return "", nil, err
+	}
+
+	metadata := map[string]interface{}{}
+	var verbs []string
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+		verbs = append(verbs, "labeled")
+	}
+	if len(annotations) > 0 {
+		metadata["annotations"] = annotations
+		verbs = append(verbs, "annotated")
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+	return strings.Join(verbs, " and "), patch, err
+}
+
+// confirmAction lists the objects to be changed on ErrOut and reads the answer
+// from In. Only "y" or "yes" confirms; without an answer, e.g. when In is not
+// a terminal and is empty, nothing is changed.
+func (o *HeadOptions) confirmAction(verb string, objs []*unstructured.Unstructured) bool {
+	fmt.Fprintf(o.ErrOut, "\nThe following %d objects will be %s:\n", len(objs), verb)
+	for _, obj := range objs {
+		fmt.Fprintf(o.ErrOut, "  %s\n", o.objectRef(obj))
+	}
+	fmt.Fprint(o.ErrOut, "Continue? [y/N]: ")
+
+	answer, err := o.readLine()
+	if err != nil {
+		fmt.Fprintln(o.ErrOut)
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes"
+}
+
+// objectRef returns how kubectl refers to an object in its results, e.g.
+// "deployment.apps/web". Across namespaces, the namespace is added as it would
+// be given to kubectl, e.g. "deployment.apps/web -n team-a".
+func (o *HeadOptions) objectRef(obj *unstructured.Unstructured) string {
+	gk := o.mapping.GroupVersionKind.GroupKind()
+	ref := schema.GroupKind{Group: gk.Group, Kind: strings.ToLower(gk.Kind)}.String() + "/" + obj.GetName()
+	if ns := obj.GetNamespace(); ns != "" && o.AllNamespaces {
+		ref += " -n " + ns
+	}
+	return ref
+}
@@ -0,0 +1,227 @@
+package head
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+func TestRun_Actions(t *testing.T) {
+	row := func(name string) metav1.TableRow {
+		return metav1.TableRow{
+			Cells: []interface{}{name},
+			Object: runtime.RawExtension{Raw: []byte(fmt.Sprintf(
+				`{"apiVersion":"meta.k8s.io/v1","kind":"PartialObjectMetadata","metadata":{"namespace":"default","name":%q,"uid":"uid-%s"}}`, name, name))},
+		}
+	}
+	table := &metav1.Table{
+		TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+		Rows:              []metav1.TableRow{row("pod-a"), row("pod-b")},
+	}
+	// newServer returns a server that lists the table and records the
+	// deletions and patches of pods, of which only existing are found.
+	newServer := func(existing []string, requests *[]string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			name, ok := strings.CutPrefix(req.URL.Path, "/api/v1/namespaces/default/pods/")
+			if !ok {
+				if err := json.NewEncoder(w).Encode(table); err != nil {
+					t.Errorf("failed to encode response: %v", err)
+				}
+				return
+			}
+			body, _ := io.ReadAll(req.Body)
+			switch req.Method {
+			case http.MethodDelete:
+				var options metav1.DeleteOptions
+				if err := json.Unmarshal(body, &options); err != nil {
+					t.Errorf("failed to decode delete options: %v", err)
+				}
+				*requests = append(*requests, fmt.Sprintf("delete %s %v %s", name, options.DryRun, *options.Preconditions.UID))
+			case http.MethodPatch:
+				*requests = append(*requests, fmt.Sprintf("patch %s %v %s", name, req.URL.Query()["dryRun"], body))
+			}
+			if !slices.Contains(existing, name) {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","message":"pods \"%s\" not found","reason":"NotFound","code":404}`, name)
+				return
+			}
+			fmt.Fprintf(w, `{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"default","name":%q}}`, name)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	testCases := []struct {
+		name        string
+		opts        HeadOptions
+		input       string
+		pods        []string
+		expectedOut string
+		// expectedRequests describes each request as verb, name, dry run and
+		// precondition or patch.
+		expectedRequests []string
+		expectedErrOut   string
+		expectedError    string
+	}{
+		{
+			name:             "delete confirmed",
+			opts:             HeadOptions{Delete: true},
+			input:            "y\n",
+			pods:             []string{"pod-a", "pod-b"},
+			expectedOut:      "pod/pod-a deleted\npod/pod-b deleted\n",
+			expectedRequests: []string{"delete pod-a [] uid-pod-a", "delete pod-b [] uid-pod-b"},
+			expectedErrOut:   "The following 2 objects will be deleted:\n  pod/pod-a\n  pod/pod-b\nContinue? [y/N]: ",
+		},
+		{
+			name:           "delete declined",
+			opts:           HeadOptions{Delete: true},
+			input:          "n\n",
+			pods:           []string{"pod-a", "pod-b"},
+			expectedErrOut: "Continue? [y/N]: Aborted.\n",
+		},
+		{
+			name:        "label dry run",
+			opts:        HeadOptions{Labels: []string{"stuck=true", "app-"}, DryRun: "server"},
+			pods:        []string{"pod-a", "pod-b"},
+			expectedOut: "pod/pod-a labeled (server dry run)\npod/pod-b labeled (server dry run)\n",
+			expectedRequests: []string{
+				`patch pod-a [All] {"metadata":{"labels":{"app":null,"stuck":"true"}}}`,
+				`patch pod-b [All] {"metadata":{"labels":{"app":null,"stuck":"true"}}}`,
+			},
+		},
+		{
+			name:        "label and annotate without confirmation",
+			opts:        HeadOptions{Labels: []string{"stuck=true"}, Annotations: []string{"example.com/reason=triage"}, Yes: true},
+			pods:        []string{"pod-a"},
+			expectedOut: "pod/pod-a labeled and annotated\n",
+			expectedRequests: []string{
+				`patch pod-a [] {"metadata":{"annotations":{"example.com/reason":"triage"},"labels":{"stuck":"true"}}}`,
+				`patch pod-b [] {"metadata":{"annotations":{"example.com/reason":"triage"},"labels":{"stuck":"true"}}}`,
+			},
+			expectedErrOut: `error: pod/pod-b: pods "pod-b" not found`,
+			expectedError:  "1 of 2 objects could not be labeled and annotated",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+			server := newServer(tc.pods, &requests)
+			dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: server.URL})
+			if err != nil {
+				t.Fatalf("failed to create dynamic client: %v", err)
+			}
+
+			streams, in, out, errOut := genericclioptions.NewTestIOStreams()
+			in.WriteString(tc.input)
+			opts := tc.opts
+			opts.Resource = "pods"
+			opts.Namespace = "default"
+			opts.Limit = 2
+			opts.Concurrency = 1
+			opts.RESTConfig = &rest.Config{Host: server.URL}
+			opts.Mapper = fakeRESTMapper()
+			opts.DynamicClient = dynamicClient
+			opts.IOStreams = streams
+			opts.PrintFlags = genericclioptions.NewPrintFlags("")
+			if err := opts.Validate(); err != nil {
+				t.Fatalf("unexpected error during Validate: %v", err)
+			}
+			err = opts.Run()
+			if tc.expectedError == "" && err != nil {
+				t.Fatalf("unexpected error during Run: %v", err)
+			}
+			if tc.expectedError != "" && (err == nil || err.Error() != tc.expectedError) {
+				t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+			}
+
+			if strings.Join(requests, "\n") != strings.Join(tc.expectedRequests, "\n") {
+				t.Errorf("expected requests:\n%s\ngot:\n%s", strings.Join(tc.expectedRequests, "\n"), strings.Join(requests, "\n"))
+			}
+			if tc.expectedOut != "" && !strings.Contains(out.String(), "\n\n"+tc.expectedOut) {
+				t.Errorf("expected output to contain:\n%s\ngot:\n%s", tc.expectedOut, out.String())
+			}
+			if !strings.Contains(errOut.String(), tc.expectedErrOut) {
+				t.Errorf("expected stderr to contain %q, got %q", tc.expectedErrOut, errOut.String())
+			}
+		})
+	}
+}
+
+func TestRun_ActionsInteractive(t *testing.T) {
+	row := func(name string) metav1.TableRow {
+		return metav1.TableRow{
+			Cells: []interface{}{name},
+			Object: runtime.RawExtension{Raw: []byte(fmt.Sprintf(
+				`{"apiVersion":"meta.k8s.io/v1","kind":"PartialObjectMetadata","metadata":{"namespace":"default","name":%q,"uid":"uid-%s"}}`, name, name))},
+		}
+	}
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if name, ok := strings.CutPrefix(req.URL.Path, "/api/v1/namespaces/default/pods/"); ok {
+			deleted = append(deleted, name)
+			fmt.Fprintf(w, `{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"default","name":%q}}`, name)
+			return
+		}
+		// Each page has a single pod.
+		table := &metav1.Table{
+			TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+			ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+			Rows:              []metav1.TableRow{row("pod-a")},
+		}
+		table.Continue = "1"
+		if req.URL.Query().Get("continue") == "1" {
+			table.Rows = []metav1.TableRow{row("pod-b")}
+			table.Continue = ""
+		}
+		if err := json.NewEncoder(w).Encode(table); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+	dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("failed to create dynamic client: %v", err)
+	}
+
+	// The page prompt and the confirmations read the same input in turn.
+	streams, in, _, _ := genericclioptions.NewTestIOStreams()
+	in.WriteString("y\nn\nyes\n")
+	opts := &HeadOptions{
+		Resource:      "pods",
+		Namespace:     "default",
+		Limit:         1,
+		Interactive:   true,
+		Delete:        true,
+		Concurrency:   1,
+		RESTConfig:    &rest.Config{Host: server.URL},
+		Mapper:        fakeRESTMapper(),
+		DynamicClient: dynamicClient,
+		IOStreams:     streams,
+		PrintFlags:    genericclioptions.NewPrintFlags(""),
+	}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("unexpected error during Validate: %v", err)
+	}
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	if expected := []string{"pod-a", "pod-b"}; !slices.Equal(deleted, expected) {
+		t.Errorf("expected deletions %v, got %v", expected, deleted)
+	}
+}
@@ -0,0 +1,79 @@
+package head
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// runAll pages through the entire list, printing each page as it arrives so
+// that no more than one page is held in memory. Progress is reported on ErrOut.
+func (o *HeadOptions) runAll(restClient rest.Interface, gvr schema.GroupVersionResource, ns string) error {
+	out := o.Out
+	var file *os.File
+	if o.OutputFile != "" {
+		var err error
+		file, err = os.Create(o.OutputFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	printer, err := o.newPagePrinter()
+	if err != nil {
+		return err
+	}
+
+	// Per-page stats replace the progress counter when requested.
+	stats := o.newStatsRecorder()
+	defer stats.Finish()
+	showProgress := stats == nil
+
+	pager := o.newPager(restClient, gvr, ns)
+	pager.Continue = o.ContinueToken
+	var items, pages int
+	for table, err := range pager.Pages(context.Background()) {
+		if err == nil {
+			stats.Record(pager.LastPageStats())
+			table, err = o.decoratePage(restClient, gvr, table)
+		}
+		if err != nil {
+			if showProgress && pages > 0 {
+				fmt.Fprintln(o.ErrOut)
+			}
+			return err
+		}
+		if err := printer.PrintPage(table, out); err != nil {
+			return err
+		}
+		if o.Logs > 0 {
+			if err := o.printLogs(table, out); err != nil {
+				return err
+			}
+		}
+		items += len(table.Rows)
+		pages++
+		if showProgress {
+			fmt.Fprintf(o.ErrOut, "\rFetched %d items in %d pages%s", items, pages, atResourceVersion(snapshotResourceVersion(table, pager.Continue)))
+		}
+	}
+	if showProgress {
+		fmt.Fprintln(o.ErrOut)
+	}
+	if err := finishPrinting(printer, out); err != nil {
+		return err
+	}
+
+	if items == 0 {
+		fmt.Fprintln(o.ErrOut, "No resources found.")
+	}
+	if file != nil {
+		return file.Close()
+	}
+	return nil
+}
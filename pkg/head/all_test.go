@@ -0,0 +1,97 @@
+package head
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+func TestRun_All(t *testing.T) {
+	var requests []string
+	client := newPagingRESTClient(t, 5, &requests)
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		return client, nil
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	outputFile := filepath.Join(t.TempDir(), "pods.txt")
+	streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:   "pods",
+		Limit:      2,
+		All:        true,
+		OutputFile: outputFile,
+		RESTConfig: &rest.Config{},
+		Mapper:     fakeRESTMapper(),
+		IOStreams:  streams,
+		PrintFlags: genericclioptions.NewPrintFlags(""),
+	}
+
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("unexpected error during Validate: %v", err)
+	}
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	output := string(data)
+	for i := 0; i < 5; i++ {
+		if !strings.Contains(output, fmt.Sprintf("pod-%d", i)) {
+			t.Errorf("expected output file to contain pod-%d, got:\n%s", i, output)
+		}
+	}
+	if strings.Count(output, "NAME") != 1 {
+		t.Errorf("expected a single header row, got:\n%s", output)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected nothing on stdout when writing to a file, got:\n%s", out.String())
+	}
+	if !strings.Contains(errOut.String(), "Fetched 5 items in 3 pages") {
+		t.Errorf("expected progress on stderr, got %q", errOut.String())
+	}
+}
+
+func TestRun_AllYAML(t *testing.T) {
+	var requests []string
+	client := newPagingRESTClient(t, 3, &requests)
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		return client, nil
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:   "pods",
+		Limit:      2,
+		All:        true,
+		RESTConfig: &rest.Config{},
+		Mapper:     fakeRESTMapper(),
+		IOStreams:  streams,
+		PrintFlags: NewHeadOptions(streams).PrintFlags.WithDefaultOutput("yaml"),
+	}
+
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	if !strings.Contains(requests[0], "includeObject=Object") {
+		t.Errorf("expected request to ask for full objects, got %q", requests[0])
+	}
+	docs := strings.Split(out.String(), "---\n")
+	if len(docs) != 3 {
+		t.Fatalf("expected 3 YAML documents, got %d:\n%s", len(docs), out.String())
+	}
+	if !strings.Contains(docs[2], "name: pod-2") {
+		t.Errorf("expected last document to be pod-2, got:\n%s", docs[2])
+	}
+}
@@ -0,0 +1,131 @@
+package head
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// RunAPIResources prints the first Limit API resources served by the cluster
+// that match APIGroup, Verbs and Namespaced, sorted by group and name. The
+// resources come from the cached discovery client that backs the Mapper,
+// which fetches all groups in a single aggregated discovery request on
+// servers that support it.
+func (o *HeadOptions) RunAPIResources() error {
+	switch o.outputFormat() {
+	case "", "wide", "name":
+	default:
+		return fmt.Errorf("--output must be one of: wide, name")
+	}
+
+	resources, err := o.preferredResources()
+	if err != nil {
+		return err
+	}
+	var matching []metav1.APIResource
+	for _, resource := range resources {
+		if o.matchesAPIResource(resource) {
+			matching = append(matching, resource)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if matching[i].Group != matching[j].Group {
+			return matching[i].Group < matching[j].Group
+		}
+		return matching[i].Name < matching[j].Name
+	})
+
+	if len(matching) == 0 {
+		fmt.Fprintln(o.ErrOut, "No resources found.")
+		return nil
+	}
+	shown := matching[:min(int64(len(matching)), o.Limit)]
+	if err := o.printAPIResources(shown); err != nil {
+		return err
+	}
+	if len(shown) < len(matching) {
+		fmt.Fprintf(o.ErrOut, "Showing %d of %d resources. Use --limit to see more, or filter with --api-group, --verbs and --namespaced.\n",
+			len(shown), len(matching))
+	}
+	return nil
+}
+
+// matchesAPIResource returns true if the resource matches the APIGroup, Verbs
+// and Namespaced filters.
+func (o *HeadOptions) matchesAPIResource(resource metav1.APIResource) bool {
+	if o.APIGroup != "" && resource.Group != o.APIGroup {
+		return false
+	}
+	if o.Namespaced != nil && resource.Namespaced != *o.Namespaced {
+		return false
+	}
+	return sets.New(resource.Verbs...).HasAll(o.Verbs...)
+}
+
+// printAPIResources prints the resources in the columns of
+// "kubectl api-resources", or only their type names for -o name.
+func (o *HeadOptions) printAPIResources(resources []metav1.APIResource) error {
+	if o.outputFormat() == "name" {
+		for _, resource := range resources {
+			fmt.Fprintln(o.Out, resourceTypeName(resource))
+		}
+		return nil
+	}
+
+	wide := o.outputFormat() == "wide"
+	w := printers.GetNewTabWriter(o.Out)
+	defer w.Flush()
+
+	header := "NAME\tSHORTNAMES\tAPIVERSION\tNAMESPACED\tKIND"
+	if wide {
+		header += "\tVERBS\tCATEGORIES"
+	}
+	fmt.Fprintln(w, header)
+	for _, resource := range resources {
+		gv := schema.GroupVersion{Group: resource.Group, Version: resource.Version}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s", resource.Name, strings.Join(resource.ShortNames, ","), gv, resource.Namespaced, resource.Kind)
+		if wide {
+			fmt.Fprintf(w, "\t%s\t%s", strings.Join(resource.Verbs, ","), strings.Join(resource.Categories, ","))
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// preferredResources returns the preferred version of each resource served by
+// the cluster, with its Group and Version set. Subresources such as pods/log
+// are left out.
+func (o *HeadOptions) preferredResources() ([]metav1.APIResource, error) {
+	discoveryClient, err := o.ConfigFlags.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	// Discovery returns partial results with an error if some groups are
+	// unavailable; those are still worth showing.
+	lists, err := discoveryClient.ServerPreferredResources()
+	if err != nil && len(lists) == 0 {
+		return nil, err
+	}
+
+	var resources []metav1.APIResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			resource.Group = gv.Group
+			resource.Version = gv.Version
+			resources = append(resources, resource)
+		}
+	}
+	return resources, nil
+}
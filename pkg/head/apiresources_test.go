@@ -0,0 +1,169 @@
+package head
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// newDiscoveryServer returns a server that serves legacy discovery for a few
+// resources in the core and apps groups. The paths of the requests are
+// appended to paths if it isn't nil.
+func newDiscoveryServer(t *testing.T, paths *[]string) *httptest.Server {
+	list, get := metav1.Verbs{"get", "list"}, metav1.Verbs{"get"}
+	appsV1 := metav1.GroupVersionForDiscovery{GroupVersion: "apps/v1", Version: "v1"}
+	apps := metav1.APIGroup{
+		TypeMeta:         metav1.TypeMeta{Kind: "APIGroup", APIVersion: "v1"},
+		Name:             "apps",
+		Versions:         []metav1.GroupVersionForDiscovery{appsV1},
+		PreferredVersion: appsV1,
+	}
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if paths != nil {
+			mu.Lock()
+			*paths = append(*paths, req.URL.Path)
+			mu.Unlock()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		var obj interface{}
+		switch req.URL.Path {
+		case "/api":
+			obj = &metav1.APIVersions{TypeMeta: metav1.TypeMeta{Kind: "APIVersions"}, Versions: []string{"v1"}}
+		case "/api/v1":
+			obj = &metav1.APIResourceList{
+				TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{
+					{Name: "pods", Namespaced: true, Kind: "Pod", ShortNames: []string{"po"}, Verbs: list},
+					{Name: "pods/log", Namespaced: true, Kind: "Pod", Verbs: get},
+					{Name: "nodes", Kind: "Node", ShortNames: []string{"no"}, Verbs: list},
+					{Name: "bindings", Namespaced: true, Kind: "Binding", Verbs: metav1.Verbs{"create"}},
+				},
+			}
+		case "/apis":
+			obj = &metav1.APIGroupList{
+				TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"},
+				Groups:   []metav1.APIGroup{apps},
+			}
+		case "/apis/apps":
+			obj = &apps
+		case "/apis/apps/v1":
+			obj = &metav1.APIResourceList{
+				TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+				GroupVersion: "apps/v1",
+				APIResources: []metav1.APIResource{
+					{Name: "deployments", SingularName: "deployment", Namespaced: true, Kind: "Deployment", ShortNames: []string{"deploy"}, Categories: []string{"all"}, Verbs: list},
+				},
+			}
+		default:
+			http.NotFound(w, req)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(obj); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunAPIResources(t *testing.T) {
+	server := newDiscoveryServer(t, nil)
+	kubeconfig := writeKubeconfig(t, map[string]string{"test": server.URL})
+	contextName := "test"
+	clusterScoped := false
+
+	testCases := []struct {
+		name           string
+		limit          int64
+		apiGroup       string
+		verbs          []string
+		namespaced     *bool
+		output         string
+		expectedOutput string
+		expectedErrOut string
+	}{
+		{
+			name:  "all resources",
+			limit: 10,
+			expectedOutput: `NAME SHORTNAMES APIVERSION NAMESPACED KIND
+bindings v1 true Binding
+nodes no v1 false Node
+pods po v1 true Pod
+deployments deploy apps/v1 true Deployment
+`,
+		},
+		{
+			name:  "first page",
+			limit: 2,
+			expectedOutput: `NAME SHORTNAMES APIVERSION NAMESPACED KIND
+bindings v1 true Binding
+nodes no v1 false Node
+`,
+			expectedErrOut: "Showing 2 of 4 resources.",
+		},
+		{
+			name:     "group",
+			limit:    10,
+			apiGroup: "apps",
+			output:   "wide",
+			expectedOutput: `NAME SHORTNAMES APIVERSION NAMESPACED KIND VERBS CATEGORIES
+deployments deploy apps/v1 true Deployment get,list all
+`,
+		},
+		{
+			name:           "verbs and scope",
+			limit:          10,
+			verbs:          []string{"list"},
+			namespaced:     &clusterScoped,
+			output:         "name",
+			expectedOutput: "nodes\n",
+		},
+		{
+			name:           "no match",
+			limit:          10,
+			apiGroup:       "batch",
+			expectedErrOut: "No resources found.",
+		},
+	}
+
+	spaces := regexp.MustCompile(` +`)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+			opts := NewHeadOptions(streams)
+			opts.ConfigFlags.KubeConfig = &kubeconfig
+			opts.ConfigFlags.Context = &contextName
+			cacheDir := t.TempDir()
+			opts.ConfigFlags.CacheDir = &cacheDir
+			opts.PrintFlags.OutputFormat = &tc.output
+			opts.Limit = tc.limit
+			opts.APIGroup = tc.apiGroup
+			opts.Verbs = tc.verbs
+			opts.Namespaced = tc.namespaced
+
+			if err := opts.Complete(""); err != nil {
+				t.Fatalf("unexpected error during Complete: %v", err)
+			}
+			if err := opts.RunAPIResources(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Collapse the padding of the columns.
+			if got := spaces.ReplaceAllString(out.String(), " "); got != tc.expectedOutput {
+				t.Errorf("expected output:\n%s\ngot:\n%s", tc.expectedOutput, got)
+			}
+			if !strings.HasPrefix(errOut.String(), tc.expectedErrOut) {
+				t.Errorf("expected stderr to start with %q, got %q", tc.expectedErrOut, errOut.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package head
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// completionLimit is the number of objects fetched to complete object names.
+// Only the first page is used, so completion stays fast on large clusters.
+const completionLimit int64 = 100
+
+// CompleteResourceTypes returns the listable resource types served by the
+// cluster that start with toComplete. Types outside the core group are
+// qualified with their group, e.g. "deployments.apps".
+func (o *HeadOptions) CompleteResourceTypes(toComplete string) []string {
+	resources, err := o.listableResources()
+	if err != nil {
+		return nil
+	}
+	names := sets.New[string]()
+	for _, resource := range resources {
+		name := resourceTypeName(resource)
+		if strings.HasPrefix(name, toComplete) {
+			names.Insert(name)
+		}
+	}
+	return sets.List(names)
+}
+
+// listableResources returns the preferred version of each resource served by
+// the cluster that can be listed.
+func (o *HeadOptions) listableResources() ([]metav1.APIResource, error) {
+	resources, err := o.preferredResources()
+	if err != nil {
+		return nil, err
+	}
+	var listable []metav1.APIResource
+	for _, resource := range resources {
+		if sets.New(resource.Verbs...).Has("list") {
+			listable = append(listable, resource)
+		}
+	}
+	return listable, nil
+}
+
+// resourceTypeName returns the name to pass as the type argument for the
+// resource: its plural name, qualified with its group outside the core group.
+func resourceTypeName(resource metav1.APIResource) string {
+	return schema.GroupResource{Group: resource.Group, Resource: resource.Name}.String()
+}
+
+// CompleteNames returns the names of the objects of the resource type on the
+// first page of a head that start with toComplete.
+func (o *HeadOptions) CompleteNames(resource, toComplete string) []string {
+	completer := &HeadOptions{
+		ConfigFlags:   o.ConfigFlags,
+		AllNamespaces: o.AllNamespaces,
+		Limit:         completionLimit,
+		// Warnings would be mixed into the completions shown in the shell.
+		IOStreams: genericclioptions.IOStreams{In: o.In, Out: o.Out, ErrOut: io.Discard},
+	}
+	if err := completer.Complete(resource); err != nil {
+		return nil
+	}
+	gvr, err := completer.GetResourceGVR()
+	if err != nil {
+		return nil
+	}
+	ns := completer.listNamespace(gvr)
+	restClient, err := newRestClient(*completer.RESTConfig, gvr.GroupVersion())
+	if err != nil {
+		return nil
+	}
+
+	table, err := completer.newPager(restClient, gvr, ns).Next(context.Background())
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, row := range table.Rows {
+		obj, err := rowObject(row)
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(obj.GetName(), toComplete) {
+			names = append(names, obj.GetName())
+		}
+	}
+	return names
+}
+
+// CompleteContexts returns the kubeconfig contexts that start with toComplete.
+func (o *HeadOptions) CompleteContexts(toComplete string) []string {
+	rawConfig, err := o.ConfigFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for name := range rawConfig.Contexts {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
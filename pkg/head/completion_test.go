@@ -0,0 +1,60 @@
+package head
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestCompletion(t *testing.T) {
+	server := newFakeAPIServer(t, "pod-east")
+	kubeconfig := writeKubeconfig(t, map[string]string{
+		"east":    server.URL,
+		"eastern": server.URL,
+		"west":    server.URL,
+	})
+	cacheDir := t.TempDir()
+	currentContext := "east"
+
+	opts := NewHeadOptions(genericclioptions.NewTestIOStreamsDiscard())
+	opts.ConfigFlags.KubeConfig = &kubeconfig
+	opts.ConfigFlags.CacheDir = &cacheDir
+	opts.ConfigFlags.Context = &currentContext
+
+	testCases := []struct {
+		name     string
+		complete func() []string
+		expected []string
+	}{
+		{
+			name:     "resource types",
+			complete: func() []string { return opts.CompleteResourceTypes("po") },
+			expected: []string{"pods"},
+		},
+		{
+			name:     "object names",
+			complete: func() []string { return opts.CompleteNames("pods", "pod-e") },
+			expected: []string{"pod-east"},
+		},
+		{
+			name:     "object names without a match",
+			complete: func() []string { return opts.CompleteNames("pods", "other") },
+			expected: nil,
+		},
+		{
+			name:     "contexts",
+			complete: func() []string { return opts.CompleteContexts("eas") },
+			expected: []string{"east", "eastern"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.complete()
+			if fmt.Sprint(got) != fmt.Sprint(tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
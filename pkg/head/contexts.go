@@ -0,0 +1,146 @@
+package head
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// contextResult is the first page of a resource within a single kubeconfig context.
+type contextResult struct {
+	context string
+	table   *metav1.Table
+	err     error
+}
+
+// isMultiContext returns true if the head should fan out across kubeconfig contexts.
+func (o *HeadOptions) isMultiContext() bool {
+	return len(o.Contexts) > 0 || o.AllContexts
+}
+
+// runContexts heads at the resource in each requested kubeconfig context
+// concurrently and prints the results as a single table with a leading CLUSTER
+// column. Contexts that fail do not stop the others; their errors are summarized
+// after the table.
+func (o *HeadOptions) runContexts() error {
+	contexts, err := o.contextNames()
+	if err != nil {
+		return err
+	}
+
+	results := make([]contextResult, len(contexts))
+	runParallel(len(contexts), o.Concurrency, func(i int) {
+		table, err := o.headContext(contexts[i])
+		results[i] = contextResult{context: contexts[i], table: table, err: err}
+	})
+
+	merged := &metav1.Table{}
+	var failed []contextResult
+	for _, result := range results {
+		// Per-namespace heads may return partial results alongside an error.
+		if result.err != nil {
+			failed = append(failed, result)
+		}
+		if result.table == nil || len(result.table.Rows) == 0 {
+			continue
+		}
+		prependColumn(result.table, "Cluster", result.context)
+		if len(merged.ColumnDefinitions) == 0 {
+			merged.ColumnDefinitions = result.table.ColumnDefinitions
+		}
+		merged.Rows = append(merged.Rows, result.table.Rows...)
+	}
+
+	if len(merged.Rows) > 0 || len(failed) < len(results) {
+		if err := o.printMerged(merged, nil); err != nil {
+			return err
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	fmt.Fprintf(o.ErrOut, "\nErrors from %d of %d contexts:\n", len(failed), len(results))
+	for _, result := range failed {
+		fmt.Fprintf(o.ErrOut, "  %s: %v\n", result.context, result.err)
+	}
+	return fmt.Errorf("%d of %d contexts failed", len(failed), len(results))
+}
+
+// contextNames returns the contexts to fan out across, either those given with
+// --contexts or every context in the kubeconfig.
+func (o *HeadOptions) contextNames() ([]string, error) {
+	if !o.AllContexts {
+		return o.Contexts, nil
+	}
+
+	rawConfig, err := o.ConfigFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(rawConfig.Contexts))
+	for name := range rawConfig.Contexts {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no contexts found in kubeconfig")
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// headContext returns the first page of the resource in the given context.
+func (o *HeadOptions) headContext(contextName string) (*metav1.Table, error) {
+	child := o.forContext(contextName)
+	if err := child.Complete(o.Resource); err != nil {
+		return nil, err
+	}
+	gvr, err := child.GetResourceGVR()
+	if err != nil {
+		return nil, err
+	}
+	restClient, err := newRestClient(*child.RESTConfig, gvr.GroupVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	if child.PerNamespace {
+		return child.headPerNamespace(restClient, gvr)
+	}
+	return child.newPager(restClient, gvr, child.listNamespace(gvr)).Next(context.Background())
+}
+
+// forContext returns a copy of the options that targets a single kubeconfig
+// context. Only the connection flags that are not specific to a cluster are
+// carried over from the original ConfigFlags.
+func (o *HeadOptions) forContext(contextName string) *HeadOptions {
+	flags := genericclioptions.NewConfigFlags(true)
+	flags.Context = &contextName
+	if o.ConfigFlags != nil {
+		flags.CacheDir = o.ConfigFlags.CacheDir
+		flags.KubeConfig = o.ConfigFlags.KubeConfig
+		flags.Namespace = o.ConfigFlags.Namespace
+		flags.Impersonate = o.ConfigFlags.Impersonate
+		flags.ImpersonateUID = o.ConfigFlags.ImpersonateUID
+		flags.ImpersonateGroup = o.ConfigFlags.ImpersonateGroup
+		flags.Timeout = o.ConfigFlags.Timeout
+		flags.DisableCompression = o.ConfigFlags.DisableCompression
+		flags.WrapConfigFn = o.ConfigFlags.WrapConfigFn
+	}
+
+	return &HeadOptions{
+		ConfigFlags:       flags,
+		PrintFlags:        o.PrintFlags,
+		Limit:             o.Limit,
+		Selector:          o.Selector,
+		APIVersion:        o.APIVersion,
+		AllNamespaces:     o.AllNamespaces,
+		PerNamespace:      o.PerNamespace,
+		NamespaceSelector: o.NamespaceSelector,
+		Concurrency:       o.Concurrency,
+		IOStreams:         o.IOStreams,
+	}
+}
@@ -0,0 +1,146 @@
+package head
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// newFakeAPIServer returns a server that serves discovery for core pods and
+// answers pod lists with a single pod named after the server.
+func newFakeAPIServer(t *testing.T, podName string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var obj interface{}
+		switch req.URL.Path {
+		case "/api":
+			obj = &metav1.APIVersions{
+				TypeMeta: metav1.TypeMeta{Kind: "APIVersions"},
+				Versions: []string{"v1"},
+			}
+		case "/apis":
+			obj = &metav1.APIGroupList{TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"}}
+		case "/api/v1":
+			obj = &metav1.APIResourceList{
+				TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{
+					{Name: "pods", Namespaced: true, Kind: "Pod", Verbs: metav1.Verbs{"get", "list"}},
+				},
+			}
+		default:
+			if !strings.HasSuffix(req.URL.Path, "/pods") {
+				http.NotFound(w, req)
+				return
+			}
+			obj = &metav1.Table{
+				TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+				ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}, {Name: "Age"}},
+				Rows:              []metav1.TableRow{{Cells: []interface{}{podName, "1d"}, Object: rawPod("default", podName)}},
+			}
+		}
+		if err := json.NewEncoder(w).Encode(obj); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeKubeconfig writes a kubeconfig with one context per server URL.
+func writeKubeconfig(t *testing.T, servers map[string]string) string {
+	var b strings.Builder
+	b.WriteString("apiVersion: v1\nkind: Config\nclusters:\n")
+	for name, url := range servers {
+		fmt.Fprintf(&b, "- name: %s\n  cluster:\n    server: %s\n", name, url)
+	}
+	b.WriteString("users:\n- name: user\n  user: {}\ncontexts:\n")
+	for name := range servers {
+		fmt.Fprintf(&b, "- name: %s\n  context:\n    cluster: %s\n    user: user\n    namespace: default\n", name, name)
+	}
+
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	return path
+}
+
+func TestRun_AllContexts(t *testing.T) {
+	east := newFakeAPIServer(t, "pod-east")
+	west := newFakeAPIServer(t, "pod-west")
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	kubeconfig := writeKubeconfig(t, map[string]string{
+		"east":   east.URL,
+		"west":   west.URL,
+		"broken": broken.URL,
+	})
+	cacheDir := t.TempDir()
+
+	streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+	opts := NewHeadOptions(streams)
+	opts.ConfigFlags.KubeConfig = &kubeconfig
+	opts.ConfigFlags.CacheDir = &cacheDir
+	opts.AllContexts = true
+	opts.Limit = 1
+
+	if err := opts.Complete("pods"); err != nil {
+		t.Fatalf("unexpected error during Complete: %v", err)
+	}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("unexpected error during Validate: %v", err)
+	}
+	err := opts.Run()
+	if err == nil || err.Error() != "1 of 3 contexts failed" {
+		t.Errorf("expected error reporting the failed context, got %v", err)
+	}
+
+	output := out.String()
+	if !strings.HasPrefix(output, "CLUSTER") {
+		t.Errorf("expected output to start with a CLUSTER column, got:\n%s", output)
+	}
+	for _, want := range []string{"east", "pod-east", "west", "pod-west"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if !strings.Contains(errOut.String(), "Errors from 1 of 3 contexts") || !strings.Contains(errOut.String(), "broken:") {
+		t.Errorf("expected an error summary for the broken context, got:\n%s", errOut.String())
+	}
+}
+
+func TestForContext(t *testing.T) {
+	kubeconfig := "/tmp/kubeconfig"
+	cluster := "other-cluster"
+	opts := NewHeadOptions(genericclioptions.NewTestIOStreamsDiscard())
+	opts.ConfigFlags.KubeConfig = &kubeconfig
+	opts.ConfigFlags.ClusterName = &cluster
+	opts.Limit = 5
+	opts.Selector = "app=web"
+
+	child := opts.forContext("prod")
+	if *child.ConfigFlags.Context != "prod" {
+		t.Errorf("expected context to be %q, got %q", "prod", *child.ConfigFlags.Context)
+	}
+	if *child.ConfigFlags.KubeConfig != kubeconfig {
+		t.Errorf("expected kubeconfig to be carried over, got %q", *child.ConfigFlags.KubeConfig)
+	}
+	if *child.ConfigFlags.ClusterName != "" {
+		t.Errorf("expected cluster override not to be carried over, got %q", *child.ConfigFlags.ClusterName)
+	}
+	if child.Limit != 5 || child.Selector != "app=web" {
+		t.Errorf("expected head flags to be carried over, got limit %d selector %q", child.Limit, child.Selector)
+	}
+}
@@ -0,0 +1,46 @@
+package head
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RunCount prints the number of objects of the resource. Where possible the
+// count comes from the remainingItemCount of a single one-item request. The
+// server omits that count for lists with a label selector, so in that case
+// every page is fetched, without the objects, and its rows are counted.
+func (o *HeadOptions) RunCount() error {
+	gvr, err := o.GetResourceGVR()
+	if err != nil {
+		return err
+	}
+	restClient, err := newRestClient(*o.RESTConfig, gvr.GroupVersion())
+	if err != nil {
+		return err
+	}
+
+	pager := o.newPager(restClient, gvr, o.listNamespace(gvr))
+	pager.Limit = 1
+	pager.IncludeObject = metav1.IncludeNone
+	first, err := pager.Next(context.Background())
+	if err != nil {
+		return err
+	}
+	count := int64(len(first.Rows))
+	if first.RemainingItemCount != nil {
+		fmt.Fprintln(o.Out, count+*first.RemainingItemCount)
+		return nil
+	}
+
+	pager.Limit = o.Limit
+	for table, err := range pager.Pages(context.Background()) {
+		if err != nil {
+			return err
+		}
+		count += int64(len(table.Rows))
+	}
+	fmt.Fprintln(o.Out, count)
+	return nil
+}
@@ -0,0 +1,66 @@
+package head
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+func TestRunCount(t *testing.T) {
+	testCases := []struct {
+		name             string
+		selector         string
+		expectedRequests int
+	}{
+		{
+			name:             "remaining item count",
+			expectedRequests: 1,
+		},
+		{
+			// One single-item request, then pages of 2 for the remaining 4 items.
+			name:             "label selector",
+			selector:         "app=web",
+			expectedRequests: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+			client := newPagingRESTClient(t, 5, &requests)
+			newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+				return client, nil
+			}
+			defer func() { newRestClient = NewRestClient }()
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			opts := &HeadOptions{
+				Resource:   "pods",
+				Limit:      2,
+				Selector:   tc.selector,
+				RESTConfig: &rest.Config{},
+				Mapper:     fakeRESTMapper(),
+				IOStreams:  streams,
+			}
+
+			if err := opts.RunCount(); err != nil {
+				t.Fatalf("unexpected error during RunCount: %v", err)
+			}
+
+			if out.String() != "5\n" {
+				t.Errorf("expected a count of 5, got %q", out.String())
+			}
+			if len(requests) != tc.expectedRequests {
+				t.Errorf("expected %d requests, got %d: %v", tc.expectedRequests, len(requests), requests)
+			}
+			for _, query := range requests {
+				if !strings.Contains(query, "includeObject=None") {
+					t.Errorf("expected requests not to fetch objects, got %q", query)
+				}
+			}
+		})
+	}
+}
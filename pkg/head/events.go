@@ -0,0 +1,138 @@
+package head
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// lastEventColumn is the column added to each page by --events.
+var lastEventColumn = metav1.TableColumnDefinition{
+	Name:        "Last Event",
+	Type:        "string",
+	Description: "The most recent event about the object.",
+}
+
+// eventsLimit is the page size of the events listed about each object, which
+// rarely has more than a few.
+const eventsLimit int64 = 50
+
+// addLastEvents adds a column to the page with the most recent event about
+// each object. The events about each object are listed with a field selector
+// on its UID, in its namespace, with at most Concurrency requests in flight.
+// Events about cluster-scoped objects are listed across all namespaces.
+// Namespaces whose events can't be listed are reported once as warnings on
+// ErrOut, and their objects are shown without events.
+func (o *HeadOptions) addLastEvents(table *metav1.Table) error {
+	client, err := kubernetes.NewForConfig(o.RESTConfig)
+	if err != nil {
+		return err
+	}
+
+	namespaces := make([]string, len(table.Rows))
+	uids := make([]types.UID, len(table.Rows))
+	for i, row := range table.Rows {
+		obj, err := rowObject(row)
+		if err != nil {
+			return err
+		}
+		namespaces[i], uids[i] = obj.GetNamespace(), obj.GetUID()
+	}
+
+	last := make([]*corev1.Event, len(table.Rows))
+	errs := make([]error, len(table.Rows))
+	runParallel(len(table.Rows), o.Concurrency, func(i int) {
+		if uids[i] != "" {
+			last[i], errs[i] = lastEvent(client.CoreV1().Events(namespaces[i]), uids[i])
+		}
+	})
+
+	now := time.Now()
+	warned := sets.New[string]()
+	table.ColumnDefinitions = append(table.ColumnDefinitions, lastEventColumn)
+	for i := range table.Rows {
+		if errs[i] != nil && !warned.Has(namespaces[i]) {
+			fmt.Fprintf(o.ErrOut, "Warning: cannot list events in namespace %q: %v\n", namespaces[i], errs[i])
+			warned.Insert(namespaces[i])
+		}
+		cell := ""
+		if last[i] != nil {
+			cell = formatEvent(last[i], now)
+		}
+		table.Rows[i].Cells = append(table.Rows[i].Cells, cell)
+	}
+	return nil
+}
+
+// lastEvent returns the most recent event about the object with the UID, or
+// nil if there is none. The server doesn't sort events, so all the events
+// about the object are paged through.
+func lastEvent(events corev1client.EventInterface, uid types.UID) (*corev1.Event, error) {
+	listOptions := metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.uid", string(uid)).String(),
+		Limit:         eventsLimit,
+	}
+
+	var last *corev1.Event
+	for {
+		list, err := events.List(context.Background(), listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			if event := &list.Items[i]; last == nil || eventTime(event).After(eventTime(last)) {
+				last = event
+			}
+		}
+		if list.Continue == "" {
+			return last, nil
+		}
+		listOptions.Continue = list.Continue
+	}
+}
+
+// eventTime returns the time the event was last seen, from whichever of the
+// fields used by the core and events.k8s.io APIs is set.
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case event.Series != nil:
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// formatEvent formats an event for the Last Event column, e.g.
+// "Warning BackOff: Back-off restarting failed container (x12 over 5m, 30s ago)".
+func formatEvent(event *corev1.Event, now time.Time) string {
+	count := event.Count
+	if event.Series != nil {
+		count = event.Series.Count
+	}
+	age := duration.HumanDuration(now.Sub(eventTime(event)))
+	if count > 1 {
+		first := event.FirstTimestamp.Time
+		if first.IsZero() {
+			first = event.EventTime.Time
+		}
+		age = fmt.Sprintf("x%d over %s, %s ago", count, duration.HumanDuration(now.Sub(first)), age)
+	} else {
+		age += " ago"
+	}
+	message := strings.Join(strings.Fields(event.Message), " ")
+	return fmt.Sprintf("%s %s: %s (%s)", event.Type, event.Reason, message, age)
+}
@@ -0,0 +1,136 @@
+package head
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+func TestRun_Events(t *testing.T) {
+	now := time.Now()
+	row := func(namespace, name string) metav1.TableRow {
+		return metav1.TableRow{
+			Cells: []interface{}{name},
+			Object: runtime.RawExtension{Raw: []byte(fmt.Sprintf(
+				`{"apiVersion":"meta.k8s.io/v1","kind":"PartialObjectMetadata","metadata":{"namespace":%q,"name":%q,"uid":"uid-%s"}}`,
+				namespace, name, name))},
+		}
+	}
+	table := &metav1.Table{
+		TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+		Rows:              []metav1.TableRow{row("default", "pod-a"), row("default", "pod-b"), row("other", "pod-c")},
+	}
+	event := func(pod, reason string, age time.Duration) corev1.Event {
+		return corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: pod + "." + reason},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod, UID: types.UID("uid-" + pod)},
+			Type:           corev1.EventTypeWarning,
+			Reason:         reason,
+			Message:        reason + " happened\nagain",
+			LastTimestamp:  metav1.NewTime(now.Add(-age)),
+			Count:          1,
+		}
+	}
+	// The events about pod-a come in two pages, with the most recent one on
+	// the first page.
+	eventPages := map[string][][]corev1.Event{
+		"uid-pod-a": {
+			{event("pod-a", "BackOff", 5*time.Minute)},
+			{event("pod-a", "Pulled", time.Hour)},
+		},
+	}
+
+	var eventQueries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var obj interface{}
+		switch req.URL.Path {
+		case "/api/v1/namespaces/default/pods":
+			obj = table
+		case "/api/v1/namespaces/default/events", "/api/v1/namespaces/other/events":
+			query := req.URL.Query()
+			eventQueries = append(eventQueries, fmt.Sprintf("%s %s limit=%s continue=%s",
+				req.URL.Path, query.Get("fieldSelector"), query.Get("limit"), query.Get("continue")))
+			list := &corev1.EventList{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "EventList"}}
+			pages := eventPages[strings.TrimPrefix(query.Get("fieldSelector"), "involvedObject.uid=")]
+			if len(pages) > 0 {
+				page := 0
+				fmt.Sscanf(query.Get("continue"), "%d", &page)
+				list.Items = pages[page]
+				if page+1 < len(pages) {
+					list.Continue = fmt.Sprint(page + 1)
+				}
+			}
+			obj = list
+		default:
+			http.NotFound(w, req)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(obj); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:    "pods",
+		Namespace:   "default",
+		Limit:       3,
+		Events:      true,
+		Concurrency: 1,
+		RESTConfig:  &rest.Config{Host: server.URL},
+		Mapper:      fakeRESTMapper(),
+		IOStreams:   streams,
+		PrintFlags:  genericclioptions.NewPrintFlags("").WithDefaultOutput("tsv"),
+	}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("unexpected error during Validate: %v", err)
+	}
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	// Each object's events are listed on their own, in its namespace.
+	expectedQueries := []string{
+		"/api/v1/namespaces/default/events involvedObject.uid=uid-pod-a limit=50 continue=",
+		"/api/v1/namespaces/default/events involvedObject.uid=uid-pod-a limit=50 continue=1",
+		"/api/v1/namespaces/default/events involvedObject.uid=uid-pod-b limit=50 continue=",
+		"/api/v1/namespaces/other/events involvedObject.uid=uid-pod-c limit=50 continue=",
+	}
+	if strings.Join(eventQueries, "\n") != strings.Join(expectedQueries, "\n") {
+		t.Errorf("expected event queries:\n%s\ngot:\n%s", strings.Join(expectedQueries, "\n"), strings.Join(eventQueries, "\n"))
+	}
+	expected := "Name\tLast Event\npod-a\tWarning BackOff: BackOff happened again (5m ago)\npod-b\t\npod-c\t\n"
+	if !strings.HasPrefix(out.String(), expected) {
+		t.Errorf("expected output to start with:\n%q\ngot:\n%q", expected, out.String())
+	}
+}
+
+func TestFormatEvent(t *testing.T) {
+	now := time.Now()
+	event := &corev1.Event{
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		FirstTimestamp: metav1.NewTime(now.Add(-5 * time.Minute)),
+		LastTimestamp:  metav1.NewTime(now.Add(-30 * time.Second)),
+		Count:          12,
+	}
+	expected := "Warning BackOff: Back-off restarting failed container (x12 over 5m, 30s ago)"
+	if got := formatEvent(event, now); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,156 @@
+package head
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/rest"
+)
+
+// validateExec checks the flags of --exec.
+func (o *HeadOptions) validateExec() error {
+	if o.Exec == "" {
+		if o.MaxPages != 0 || o.PageDelay != 0 {
+			return fmt.Errorf("--max-pages and --page-delay can only be used with --exec")
+		}
+		return nil
+	}
+	if o.MaxPages < 0 {
+		return fmt.Errorf("--max-pages must be a positive number")
+	}
+	if o.PageDelay < 0 {
+		return fmt.Errorf("--page-delay must not be negative")
+	}
+	if o.Interactive || o.All || o.PerNamespace || o.isMultiContext() || o.Logs > 0 || o.hasActions() {
+		return fmt.Errorf("--exec cannot be used with --interactive, --all, --per-namespace, --contexts, --all-contexts, --logs, --delete, --label or --annotate")
+	}
+	if o.outputFormat() == "" && o.AllNamespaces {
+		return fmt.Errorf("--exec pipes names without their namespaces by default; use an output format such as -o json with --all-namespaces")
+	}
+	if o.outputFormat() == "" && (o.Events || o.ShowOwner || o.WithMetrics) {
+		return fmt.Errorf("--exec pipes names by default; use a table format such as -o tsv with --events, --show-owner or --with-metrics")
+	}
+	return nil
+}
+
+// newExecPrinter returns the printer for the pages piped to Exec, which prints
+// names, as -o name does, unless an output format is requested.
+func (o *HeadOptions) newExecPrinter() (pagePrinter, error) {
+	if o.outputFormat() == "" {
+		return &objectPagePrinter{printer: &printers.NamePrinter{}}, nil
+	}
+	return o.newPagePrinter()
+}
+
+// execEnv returns the environment of the command run by Exec, which is told the
+// namespace listed and the kubeconfig context, since the names it is piped
+// don't carry them: KUBECTL_HEAD_NAMESPACE and KUBECTL_HEAD_CONTEXT are set,
+// and KUBECONFIG too if --kubeconfig was given.
+func (o *HeadOptions) execEnv(ns string) []string {
+	env := append(os.Environ(), "KUBECTL_HEAD_NAMESPACE="+ns)
+	if o.ConfigFlags == nil {
+		return env
+	}
+	if o.ConfigFlags.KubeConfig != nil && *o.ConfigFlags.KubeConfig != "" {
+		env = append(env, "KUBECONFIG="+*o.ConfigFlags.KubeConfig)
+	}
+	context := ""
+	if o.ConfigFlags.Context != nil {
+		context = *o.ConfigFlags.Context
+	}
+	if context == "" {
+		if config, err := o.ConfigFlags.ToRawKubeConfigLoader().RawConfig(); err == nil {
+			context = config.CurrentContext
+		}
+	}
+	return append(env, "KUBECTL_HEAD_CONTEXT="+context)
+}
+
+// runExec pages through the list and pipes each page to Exec, run with sh -c,
+// in the requested output format or as names by default. The next page is
+// only fetched once the command succeeds, so a failed page can be retried with
+// the continue token reported in the error. Paging stops after MaxPages pages,
+// if set, reporting the token to resume from, and waits PageDelay between
+// pages to spread the load on the API server.
+func (o *HeadOptions) runExec(restClient rest.Interface, gvr schema.GroupVersionResource, ns string) error {
+	if o.ContinueToken != "" {
+		o.warnIfStaleContinue(restClient, gvr, ns)
+	}
+
+	// Per-page stats replace the progress lines when requested.
+	stats := o.newStatsRecorder()
+	defer stats.Finish()
+	showProgress := stats == nil
+
+	pager := o.newPager(restClient, gvr, ns)
+	if o.outputFormat() == "" {
+		// Names are printed from the kind of the embedded objects.
+		pager.IncludeObject = metav1.IncludeObject
+	}
+	pager.Continue = o.ContinueToken
+	// token fetches the current page, to retry it if the command fails.
+	token := o.ContinueToken
+	env := o.execEnv(ns)
+	var items, pages int
+	for table, err := range pager.Pages(context.Background()) {
+		if err == nil {
+			stats.Record(pager.LastPageStats())
+			table, err = o.decoratePage(restClient, gvr, table)
+		}
+		if err != nil {
+			return err
+		}
+		if pages == 0 && len(table.Rows) == 0 {
+			fmt.Fprintln(o.ErrOut, "No resources found.")
+			return nil
+		}
+
+		// Each page is printed as a complete document for the command.
+		printer, err := o.newExecPrinter()
+		if err != nil {
+			return err
+		}
+		var input bytes.Buffer
+		if err := printer.PrintPage(table, &input); err != nil {
+			return err
+		}
+		if err := finishPrinting(printer, &input); err != nil {
+			return err
+		}
+
+		cmd := exec.Command("sh", "-c", o.Exec)
+		cmd.Stdin = &input
+		cmd.Env = env
+		cmd.Stdout = o.Out
+		cmd.Stderr = o.ErrOut
+		if err := cmd.Run(); err != nil {
+			if token == "" {
+				return fmt.Errorf("--exec failed on page %d: %w", pages+1, err)
+			}
+			return fmt.Errorf("--exec failed on page %d: %w; retry it with --continue %s", pages+1, err, token)
+		}
+		token = pager.Continue
+		items += len(table.Rows)
+		pages++
+		if showProgress {
+			fmt.Fprintf(o.ErrOut, "Processed %d items in %d pages%s\n", items, pages, atResourceVersion(snapshotResourceVersion(table, pager.Continue)))
+		}
+
+		if pager.Done() {
+			return nil
+		}
+		if o.MaxPages > 0 && pages >= o.MaxPages {
+			fmt.Fprintf(o.ErrOut, "Stopped after %d pages; resume with --continue %s\n", pages, token)
+			return nil
+		}
+		time.Sleep(o.PageDelay)
+	}
+	return nil
+}
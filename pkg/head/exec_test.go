@@ -0,0 +1,164 @@
+package head
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+func TestRun_Exec(t *testing.T) {
+	testCases := []struct {
+		name             string
+		command          string
+		maxPages         int
+		expectedOut      string
+		expectedRequests int
+		expectedErrOut   string
+		expectedError    string
+	}{
+		{
+			name:             "every page",
+			command:          "sed 's/^/> /'",
+			expectedOut:      "> pod/pod-0\n> pod/pod-1\n> pod/pod-2\n> pod/pod-3\n> pod/pod-4\n",
+			expectedRequests: 3,
+			expectedErrOut:   "Processed 5 items in 3 pages\n",
+		},
+		{
+			name:             "max pages",
+			command:          "cat",
+			maxPages:         2,
+			expectedOut:      "pod/pod-0\npod/pod-1\npod/pod-2\npod/pod-3\n",
+			expectedRequests: 2,
+			expectedErrOut:   "Stopped after 2 pages; resume with --continue 4\n",
+		},
+		{
+			name:             "command fails",
+			command:          `input=$(cat) && ! echo "$input" | grep -q pod-2 && echo "$input"`,
+			expectedOut:      "pod/pod-0\npod/pod-1\n",
+			expectedRequests: 2,
+			expectedError:    "--exec failed on page 2: exit status 1; retry it with --continue 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+			client := newPagingRESTClient(t, 5, &requests)
+			newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+				return client, nil
+			}
+			defer func() { newRestClient = NewRestClient }()
+
+			streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+			opts := &HeadOptions{
+				Resource:   "pods",
+				Limit:      2,
+				Exec:       tc.command,
+				MaxPages:   tc.maxPages,
+				RESTConfig: &rest.Config{},
+				Mapper:     fakeRESTMapper(),
+				IOStreams:  streams,
+				PrintFlags: genericclioptions.NewPrintFlags(""),
+			}
+			if err := opts.Validate(); err != nil {
+				t.Fatalf("unexpected error during Validate: %v", err)
+			}
+			err := opts.Run()
+			if tc.expectedError == "" && err != nil {
+				t.Fatalf("unexpected error during Run: %v", err)
+			}
+			if tc.expectedError != "" && (err == nil || err.Error() != tc.expectedError) {
+				t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+			}
+
+			if format := *opts.PrintFlags.OutputFormat; format != "" {
+				t.Errorf("expected the output format to be left unset, got %q", format)
+			}
+			if out.String() != tc.expectedOut {
+				t.Errorf("expected output:\n%s\ngot:\n%s", tc.expectedOut, out.String())
+			}
+			// The next page is only fetched after the command succeeds.
+			if len(requests) != tc.expectedRequests {
+				t.Errorf("expected %d list requests, got %d: %v", tc.expectedRequests, len(requests), requests)
+			}
+			if !strings.HasSuffix(errOut.String(), tc.expectedErrOut) {
+				t.Errorf("expected stderr to end with %q, got %q", tc.expectedErrOut, errOut.String())
+			}
+		})
+	}
+}
+
+func TestRun_ExecStats(t *testing.T) {
+	var requests []string
+	client := newPagingRESTClient(t, 5, &requests)
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		return client, nil
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	streams, _, _, errOut := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:   "pods",
+		Limit:      2,
+		Exec:       "cat >/dev/null",
+		Stats:      "json",
+		RESTConfig: &rest.Config{},
+		Mapper:     fakeRESTMapper(),
+		IOStreams:  streams,
+		PrintFlags: genericclioptions.NewPrintFlags(""),
+	}
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	// The stats of each page replace the progress lines.
+	var types []string
+	for _, line := range strings.Split(strings.TrimSpace(errOut.String()), "\n") {
+		var record statsRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid JSON stats line %q: %v", line, err)
+		}
+		types = append(types, record.Type)
+	}
+	if expected := []string{"page", "page", "page", "summary"}; fmt.Sprint(types) != fmt.Sprint(expected) {
+		t.Errorf("expected stats records %v, got %v", expected, types)
+	}
+}
+
+func TestRun_ExecEnv(t *testing.T) {
+	var requests []string
+	client := newPagingRESTClient(t, 1, &requests)
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		return client, nil
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	configFlags := genericclioptions.NewConfigFlags(false)
+	context, kubeconfig := "prod", "/tmp/kubeconfig"
+	configFlags.Context, configFlags.KubeConfig = &context, &kubeconfig
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		ConfigFlags: configFlags,
+		Resource:    "pods",
+		Namespace:   "team-a",
+		Limit:       2,
+		Exec:        `cat >/dev/null && echo "$KUBECTL_HEAD_NAMESPACE $KUBECTL_HEAD_CONTEXT $KUBECONFIG"`,
+		RESTConfig:  &rest.Config{},
+		Mapper:      fakeRESTMapper(),
+		IOStreams:   streams,
+		PrintFlags:  genericclioptions.NewPrintFlags(""),
+	}
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	// The names piped to the command don't say where the objects are.
+	if expected := "team-a prod /tmp/kubeconfig\n"; out.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, out.String())
+	}
+}
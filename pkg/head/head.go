@@ -4,14 +4,16 @@ import (
 	"bufio"
 	"context"
 	"fmt"
-	"os"
+	"io"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
-	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -20,6 +22,11 @@ import (
 const (
 	// DefaultHeadLimit is the default number of items to return per page.
 	DefaultHeadLimit int64 = 10
+	// DefaultExportLimit is the default number of items per page when paging
+	// through an entire list.
+	DefaultExportLimit int64 = 500
+	// DefaultConcurrency is the default number of parallel requests when fanning out.
+	DefaultConcurrency = 8
 )
 
 // HeadOptions provides the options and dependencies for the head command.
@@ -37,12 +44,100 @@ type HeadOptions struct {
 	Selector      string
 	AllNamespaces bool
 
+	// ResourceVersion and ResourceVersionMatch select the snapshot to read the
+	// first page from, e.g. "0" for a cached read instead of a quorum read.
+	ResourceVersion      string
+	ResourceVersionMatch string
+
+	// PerNamespace shows the first Limit items from each namespace, optionally
+	// restricted to the namespaces matching NamespaceSelector.
+	PerNamespace      bool
+	NamespaceSelector string
+	// Wide includes the columns shown by -o wide in csv, tsv, markdown and
+	// html output.
+	Wide bool
+	// LinkTemplate is a Go template, executed against each object, for the URL
+	// that names link to in markdown and html output.
+	LinkTemplate string
+
+	// APIVersion is the group/version (e.g. "example.com/v1beta1") to list the
+	// resource in, instead of the version preferred by the server.
+	APIVersion string
+
+	// Subresource fetches the named subresource (e.g. "scale") of each object
+	// in a page and prints it instead of the object.
+	Subresource string
+
+	// APIGroup, Verbs and Namespaced filter the resources listed by
+	// RunAPIResources. A nil Namespaced lists resources of both scopes.
+	APIGroup   string
+	Verbs      []string
+	Namespaced *bool
+
+	// Logs prints the last Logs lines of the logs of each pod in a page, or
+	// the first Logs lines if LogsFirst is set. Zero disables logs.
+	Logs      int64
+	LogsFirst bool
+
+	// Events adds a column with the most recent event about each object.
+	Events bool
+
+	// ShowOwner adds a column with the controller of each object, followed up
+	// to the top-level owner if TopOwner is set.
+	ShowOwner bool
+	TopOwner  bool
+
+	// WithMetrics adds columns with the CPU and memory usage of each pod or
+	// node, from metrics.k8s.io.
+	WithMetrics bool
+
+	// Delete deletes the objects in the printed page, and Labels and
+	// Annotations set (KEY=VALUE) or remove (KEY-) their labels and
+	// annotations. The changes are confirmed on the terminal unless Yes is set,
+	// and only submitted as a dry run if DryRun is "server".
+	Delete      bool
+	Labels      []string
+	Annotations []string
+	DryRun      string
+	Yes         bool
+
+	// Exec is a shell command run for each page, with the page piped to its
+	// stdin. Paging stops after MaxPages pages if set, and waits PageDelay
+	// between pages.
+	Exec      string
+	MaxPages  int
+	PageDelay time.Duration
+
+	// Stats reports request latency, size and source for each page on ErrOut,
+	// either as "human" readable lines or as "json".
+	Stats string
+
+	// All pages through the entire list, streaming each page to OutputFile
+	// (or Out if empty) as it arrives.
+	All        bool
+	OutputFile string
+
+	// Contexts lists the kubeconfig contexts to head at concurrently. If
+	// AllContexts is set, every context in the kubeconfig is used instead.
+	Contexts    []string
+	AllContexts bool
+	// Concurrency bounds the number of requests made in parallel when fanning out.
+	Concurrency int
+
 	// Calculated values.
 	Namespace     string
 	DynamicClient dynamic.Interface
 	Mapper        meta.RESTMapper
 	RESTConfig    *rest.Config
 
+	// mapping is the REST mapping most recently resolved for Resource.
+	mapping *meta.RESTMapping
+	// ownerResolver caches the owners looked up by --top-owner across pages.
+	ownerResolver *ownerResolver
+	// inReader buffers In, shared by the page and confirmation prompts so
+	// neither loses input read ahead by the other.
+	inReader *bufio.Reader
+
 	genericclioptions.IOStreams
 }
 
@@ -51,6 +146,7 @@ func NewHeadOptions(streams genericclioptions.IOStreams) *HeadOptions {
 	return &HeadOptions{
 		ConfigFlags: genericclioptions.NewConfigFlags(true),
 		PrintFlags:  genericclioptions.NewPrintFlags("").WithTypeSetter(scheme.Scheme),
+		Concurrency: DefaultConcurrency,
 		IOStreams:   streams,
 	}
 }
@@ -60,6 +156,11 @@ func (o *HeadOptions) Complete(resource string) error {
 	var err error
 	o.Resource = resource
 
+	// Clients for each context are created when the head fans out in Run.
+	if o.isMultiContext() {
+		return nil
+	}
+
 	// Create a RESTMapper to map resource names (like "pods") to GVRs.
 	o.Mapper, err = o.ConfigFlags.ToRESTMapper()
 	if err != nil {
@@ -97,6 +198,126 @@ func (o *HeadOptions) Validate() error {
 	if o.Interactive && (*o.PrintFlags.OutputFormat != "" && *o.PrintFlags.OutputFormat != "wide") {
 		return fmt.Errorf("interactive mode is only supported for standard and wide table output")
 	}
+	if err := o.validateResourceVersion(); err != nil {
+		return err
+	}
+	if o.PerNamespace && (o.Interactive || o.ContinueToken != "") {
+		return fmt.Errorf("--per-namespace cannot be used with --interactive or --continue")
+	}
+	if o.NamespaceSelector != "" && !o.PerNamespace {
+		return fmt.Errorf("--namespace-selector can only be used with --per-namespace")
+	}
+	if o.Wide && !documentFormats[o.outputFormat()] {
+		return fmt.Errorf("--wide can only be used with -o csv, tsv, markdown or html")
+	}
+	if o.LinkTemplate != "" && o.outputFormat() != "markdown" && o.outputFormat() != "html" {
+		return fmt.Errorf("--link-template can only be used with -o markdown or html")
+	}
+	if o.Stats != "" && o.Stats != "human" && o.Stats != "json" {
+		return fmt.Errorf("--stats must be one of: human, json")
+	}
+	if o.Stats != "" && (o.PerNamespace || o.isMultiContext()) {
+		return fmt.Errorf("--stats cannot be used with --per-namespace, --contexts or --all-contexts")
+	}
+	if o.All && (o.Interactive || o.PerNamespace || o.isMultiContext()) {
+		return fmt.Errorf("--all cannot be used with --interactive, --per-namespace, --contexts or --all-contexts")
+	}
+	if o.OutputFile != "" && !o.All {
+		return fmt.Errorf("--output-file can only be used with --all")
+	}
+	if o.isMultiContext() && (o.Interactive || o.ContinueToken != "") {
+		return fmt.Errorf("--contexts and --all-contexts cannot be used with --interactive or --continue")
+	}
+	if len(o.Contexts) > 0 && o.AllContexts {
+		return fmt.Errorf("cannot use --contexts and --all-contexts flags together")
+	}
+	if o.isMultiContext() && o.ConfigFlags != nil && o.ConfigFlags.Context != nil && *o.ConfigFlags.Context != "" {
+		return fmt.Errorf("cannot use --context with --contexts or --all-contexts")
+	}
+	if err := o.validateAPIVersion(); err != nil {
+		return err
+	}
+	if o.Subresource != "" && !slices.Contains(supportedSubresources, o.Subresource) {
+		return fmt.Errorf("--subresource must be one of: %s", strings.Join(supportedSubresources, ", "))
+	}
+	if o.Subresource != "" && (o.PerNamespace || o.isMultiContext()) {
+		return fmt.Errorf("--subresource cannot be used with --per-namespace, --contexts or --all-contexts")
+	}
+	if o.Logs < 0 {
+		return fmt.Errorf("--logs must be a positive number of lines")
+	}
+	if o.LogsFirst && o.Logs == 0 {
+		return fmt.Errorf("--logs-first can only be used with --logs")
+	}
+	if o.Logs > 0 && o.outputFormat() != "" && o.outputFormat() != "wide" {
+		return fmt.Errorf("--logs can only be used with the default table output or -o wide")
+	}
+	if o.Logs > 0 && (o.PerNamespace || o.isMultiContext() || o.Subresource != "") {
+		return fmt.Errorf("--logs cannot be used with --per-namespace, --contexts, --all-contexts or --subresource")
+	}
+	if o.Events && !tableFormats[o.outputFormat()] {
+		return fmt.Errorf("--events can only be used with table output: the default, -o wide, csv, tsv, markdown or html")
+	}
+	if o.Events && (o.PerNamespace || o.isMultiContext() || o.Subresource != "") {
+		return fmt.Errorf("--events cannot be used with --per-namespace, --contexts, --all-contexts or --subresource")
+	}
+	if o.TopOwner && !o.ShowOwner {
+		return fmt.Errorf("--top-owner can only be used with --show-owner")
+	}
+	if o.ShowOwner && !tableFormats[o.outputFormat()] {
+		return fmt.Errorf("--show-owner can only be used with table output: the default, -o wide, csv, tsv, markdown or html")
+	}
+	if o.ShowOwner && (o.PerNamespace || o.isMultiContext() || o.Subresource != "") {
+		return fmt.Errorf("--show-owner cannot be used with --per-namespace, --contexts, --all-contexts or --subresource")
+	}
+	if o.WithMetrics && !tableFormats[o.outputFormat()] {
+		return fmt.Errorf("--with-metrics can only be used with table output: the default, -o wide, csv, tsv, markdown or html")
+	}
+	if o.WithMetrics && (o.PerNamespace || o.isMultiContext() || o.Subresource != "") {
+		return fmt.Errorf("--with-metrics cannot be used with --per-namespace, --contexts, --all-contexts or --subresource")
+	}
+	if (o.PerNamespace || o.isMultiContext() || o.Subresource != "" || o.Logs > 0 || o.Events || o.WithMetrics) && o.Concurrency <= 0 {
+		return fmt.Errorf("--concurrency must be a positive number")
+	}
+	if err := o.validateActions(); err != nil {
+		return err
+	}
+	return o.validateExec()
+}
+
+// validateAPIVersion checks that --api-version is a group/version, or a
+// version of the core group.
+func (o *HeadOptions) validateAPIVersion() error {
+	if o.APIVersion == "" {
+		return nil
+	}
+	gv, err := schema.ParseGroupVersion(o.APIVersion)
+	if err != nil || !apiVersionPattern.MatchString(gv.Version) {
+		return fmt.Errorf("--api-version must be of the form group/version, or version for the core group (e.g. apps/v1 or v1)")
+	}
+	return nil
+}
+
+// validateResourceVersion rejects the combinations of resource version flags
+// that the API server forbids for list requests.
+func (o *HeadOptions) validateResourceVersion() error {
+	switch metav1.ResourceVersionMatch(o.ResourceVersionMatch) {
+	case "", metav1.ResourceVersionMatchNotOlderThan, metav1.ResourceVersionMatchExact:
+	default:
+		return fmt.Errorf("--resource-version-match must be one of: %s, %s", metav1.ResourceVersionMatchNotOlderThan, metav1.ResourceVersionMatchExact)
+	}
+	if o.ResourceVersionMatch != "" && o.ResourceVersion == "" {
+		return fmt.Errorf("--resource-version-match requires --resource-version")
+	}
+	if metav1.ResourceVersionMatch(o.ResourceVersionMatch) == metav1.ResourceVersionMatchExact && o.ResourceVersion == "0" {
+		return fmt.Errorf("--resource-version-match=%s cannot be used with --resource-version=0", metav1.ResourceVersionMatchExact)
+	}
+	if o.ResourceVersion != "" && o.ContinueToken != "" {
+		return fmt.Errorf("cannot use --resource-version with --continue; the continue token already selects a resource version")
+	}
+	if o.ResourceVersion != "" && o.isMultiContext() {
+		return fmt.Errorf("cannot use --resource-version with --contexts or --all-contexts; resource versions are specific to a cluster")
+	}
 	return nil
 }
 
@@ -104,14 +325,19 @@ func (o *HeadOptions) Validate() error {
 var newRestClient = NewRestClient
 
 func (o *HeadOptions) Run() error {
+	if o.isMultiContext() {
+		return o.runContexts()
+	}
+
 	gvr, err := o.GetResourceGVR()
 	if err != nil {
 		return err
 	}
-
-	ns := o.Namespace
-	if o.AllNamespaces {
-		ns = "" // An empty string tells the client to query all namespaces.
+	if o.Logs > 0 && gvr.GroupResource() != podsGVR.GroupResource() {
+		return fmt.Errorf("--logs can only be used with pods")
+	}
+	if _, ok := metricsGVRs[gvr.GroupResource()]; o.WithMetrics && !ok {
+		return fmt.Errorf("--with-metrics can only be used with pods or nodes")
 	}
 
 	// We need a REST client that can negotiate for Table output.
@@ -120,26 +346,44 @@ func (o *HeadOptions) Run() error {
 		return err
 	}
 
-	continueToken := o.ContinueToken
-	isFirstRequest := true
+	if o.PerNamespace {
+		table, err := o.headPerNamespace(restClient, gvr)
+		return o.printMerged(table, err)
+	}
 
-	for {
-		listOptions := metav1.ListOptions{
-			Limit:         o.Limit,
-			Continue:      continueToken,
-			LabelSelector: o.Selector,
-		}
+	ns := o.listNamespace(gvr)
+
+	if o.All {
+		return o.runAll(restClient, gvr, ns)
+	}
+	if o.Exec != "" {
+		return o.runExec(restClient, gvr, ns)
+	}
+
+	printer, err := o.newPagePrinter()
+	if err != nil {
+		return err
+	}
+
+	if o.ContinueToken != "" {
+		o.warnIfStaleContinue(restClient, gvr, ns)
+	}
 
-		table := &metav1.Table{}
-		err := restClient.Get().
-			Namespace(ns).
-			Resource(gvr.Resource).
-			VersionedParams(&listOptions, scheme.ParameterCodec).
-			Do(context.Background()).
-			Into(table)
+	stats := o.newStatsRecorder()
+	defer stats.Finish()
+
+	pager := o.newPager(restClient, gvr, ns)
+	pager.Continue = o.ContinueToken
+	isFirstRequest := true
+
+	for table, err := range pager.Pages(context.Background()) {
 		if err != nil {
 			return err
 		}
+		stats.Record(pager.LastPageStats())
+		if table, err = o.decoratePage(restClient, gvr, table); err != nil {
+			return err
+		}
 
 		// If it's the first page and there are no items, just say so and exit.
 		if isFirstRequest && len(table.Rows) == 0 {
@@ -147,41 +391,189 @@ func (o *HeadOptions) Run() error {
 			return nil
 		}
 
-		// Directly create a table printer to ensure correct output.
-		printer := printers.NewTablePrinter(printers.PrintOptions{})
-		if err := printer.PrintObj(table, o.Out); err != nil {
+		if err := printer.PrintPage(table, o.Out); err != nil {
 			return err
 		}
+		if o.Logs > 0 {
+			if err := o.printLogs(table, o.Out); err != nil {
+				return err
+			}
+		}
+		// Only interactive mode prints more than one page.
+		if !o.Interactive {
+			if err := finishPrinting(printer, o.Out); err != nil {
+				return err
+			}
+		}
+		if o.hasActions() {
+			if err := o.applyActions(table, gvr); err != nil {
+				return err
+			}
+		}
 
 		isFirstRequest = false
-		continueToken = table.Continue
 
 		// If there's no token, we've reached the end of the list.
-		if continueToken == "" {
-			if o.Interactive {
-				fmt.Fprintln(o.Out, "\n--- End of list ---")
+		rv := snapshotResourceVersion(table, pager.Continue)
+		if pager.Done() {
+			switch {
+			case o.Interactive:
+				fmt.Fprintf(o.Out, "\n--- End of list ---%s\n", atResourceVersion(rv))
+			case rv != "":
+				fmt.Fprintf(o.infoOut(), "\nResource Version: %s\n", rv)
 			}
 			return nil
 		}
 
 		// Handle pagination flow.
 		if o.Interactive {
-			fmt.Fprintf(o.Out, "\n--- [n] next page, [q] quit: ")
-			reader := bufio.NewReader(os.Stdin)
-			char, _, err := reader.ReadRune()
+			fmt.Fprintf(o.Out, "\n--- resourceVersion %s --- [n] next page, [q] quit: ", rv)
+			answer, err := o.readLine()
 			if err != nil {
 				return err
 			}
-			fmt.Println() // Newline for clean formatting after user input.
-			if char != 'n' {
+			fmt.Fprintln(o.Out) // Newline for clean formatting after user input.
+			if !strings.HasPrefix(answer, "n") {
 				return nil // Quit on any key other than 'n'.
 			}
 		} else {
-			// In non-interactive mode, print the token and exit.
-			fmt.Fprintf(o.Out, "\nContinue Token: %s\n", continueToken)
+			// In non-interactive mode, print the token and the snapshot it reads
+			// from, then exit.
+			fmt.Fprintf(o.infoOut(), "\nResource Version: %s\nContinue Token: %s\n", rv, pager.Continue)
 			return nil
 		}
 	}
+	return nil
+}
+
+// readLine reads a line of input from In, without surrounding whitespace. The
+// whole line is consumed, so the next prompt starts on fresh input.
+func (o *HeadOptions) readLine() (string, error) {
+	if o.In == nil {
+		return "", io.EOF
+	}
+	if o.inReader == nil {
+		o.inReader = bufio.NewReader(o.In)
+	}
+	line, err := o.inReader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// infoOut returns where to print information about the list, such as its
+// resourceVersion and continue token. Machine-readable output keeps it on
+// stderr so it doesn't corrupt the document.
+func (o *HeadOptions) infoOut() io.Writer {
+	if o.outputFormat() != "" && o.outputFormat() != "wide" {
+		return o.ErrOut
+	}
+	return o.Out
+}
+
+// snapshotResourceVersion returns the resourceVersion of the snapshot a page
+// was read from: the one pinned by the continue token to the next page if it
+// can be decoded, which the rest of the list is read from too, or otherwise
+// the one reported with the page.
+func snapshotResourceVersion(table *metav1.Table, continueToken string) string {
+	if token, err := DecodeContinueToken(continueToken); err == nil {
+		return strconv.FormatInt(token.ResourceVersion, 10)
+	}
+	return table.ResourceVersion
+}
+
+// atResourceVersion returns " at resourceVersion RV" to add to a message about
+// a page, or "" if the server didn't report one.
+func atResourceVersion(rv string) string {
+	if rv == "" {
+		return ""
+	}
+	return " at resourceVersion " + rv
+}
+
+// printMerged prints a table assembled from several requests, followed by the
+// error from any requests that failed.
+func (o *HeadOptions) printMerged(table *metav1.Table, err error) error {
+	if table == nil || len(table.Rows) == 0 {
+		if err == nil {
+			fmt.Fprintln(o.Out, "No resources found.")
+		}
+		return err
+	}
+
+	printer, printErr := o.newPagePrinter()
+	if printErr != nil {
+		return printErr
+	}
+	if printErr := printer.PrintPage(table, o.Out); printErr != nil {
+		return printErr
+	}
+	if printErr := finishPrinting(printer, o.Out); printErr != nil {
+		return printErr
+	}
+	return err
+}
+
+// listNamespace returns the namespace to list the resource in, which is empty
+// for all namespaces and for cluster-scoped resources.
+func (o *HeadOptions) listNamespace(gvr schema.GroupVersionResource) string {
+	if o.AllNamespaces {
+		return "" // An empty string tells the client to query all namespaces.
+	}
+	if namespaced, err := o.isNamespaced(gvr); err == nil && !namespaced {
+		return ""
+	}
+	return o.Namespace
+}
+
+// newPager returns a Pager for the resource in the namespace using the
+// limit and selector from the options.
+func (o *HeadOptions) newPager(restClient rest.Interface, gvr schema.GroupVersionResource, ns string) *Pager {
+	pager := NewPager(restClient, gvr, ns)
+	pager.Limit = o.Limit
+	pager.LabelSelector = o.Selector
+	pager.ResourceVersion = o.ResourceVersion
+	pager.ResourceVersionMatch = metav1.ResourceVersionMatch(o.ResourceVersionMatch)
+	switch {
+	case o.Subresource != "":
+		// Only the names are needed to fetch the subresources.
+		pager.IncludeObject = metav1.IncludeMetadata
+	case o.wantsObjects() || o.Logs > 0:
+		// Logs need the containers from the pod specs.
+		pager.IncludeObject = metav1.IncludeObject
+	case o.Events || o.ShowOwner || o.WithMetrics || o.hasActions():
+		// Only the metadata is needed for the extra columns and the actions.
+		pager.IncludeObject = metav1.IncludeMetadata
+	}
+	return pager
+}
+
+// decoratePage replaces the objects in a page with their subresource, and adds
+// the columns requested by --events, --show-owner and --with-metrics.
+func (o *HeadOptions) decoratePage(restClient rest.Interface, gvr schema.GroupVersionResource, table *metav1.Table) (*metav1.Table, error) {
+	var err error
+	if o.Subresource != "" {
+		if table, err = o.subresourceTable(restClient, gvr, table); err != nil {
+			return nil, err
+		}
+	}
+	if o.Events {
+		if err := o.addLastEvents(table); err != nil {
+			return nil, err
+		}
+	}
+	if o.ShowOwner {
+		if err := o.addOwners(table); err != nil {
+			return nil, err
+		}
+	}
+	if o.WithMetrics {
+		if err := o.addMetrics(table, gvr); err != nil {
+			return nil, err
+		}
+	}
+	return table, nil
 }
 
 // NewRestClient creates a REST client configured to request Table-formatted server-side printing.
@@ -197,25 +589,11 @@ func NewRestClient(config rest.Config, gv schema.GroupVersion) (rest.Interface,
 	return rest.RESTClientFor(&config)
 }
 
-// GetResourceGVR finds the GroupVersionResource for a given short resource name.
+// GetResourceGVR finds the GroupVersionResource for a resource argument.
 func (o *HeadOptions) GetResourceGVR() (schema.GroupVersionResource, error) {
-	resourceArg := strings.ToLower(o.Resource)
-
-	// Create a partial GVR from the user's argument. We don't know the version,
-	// so we leave it empty. The RESTMapper will find the best match.
-	// This approach handles "pods", "deployments", and "deployments.apps" style arguments.
-	gvrToFind := schema.GroupVersionResource{}
-	parts := strings.Split(resourceArg, ".")
-	if len(parts) == 2 {
-		gvrToFind = schema.GroupVersionResource{Group: parts[1], Resource: parts[0]}
-	} else {
-		gvrToFind = schema.GroupVersionResource{Resource: resourceArg}
-	}
-
-	gvr, err := o.Mapper.ResourceFor(gvrToFind)
+	mapping, err := o.resourceMapping()
 	if err != nil {
-		return schema.GroupVersionResource{}, fmt.Errorf("the server doesn't have a resource type %q", o.Resource)
+		return schema.GroupVersionResource{}, err
 	}
-
-	return gvr, nil
-}
\ No newline at end of file
+	return mapping.Resource, nil
+}
@@ -15,8 +15,11 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	clienttesting "k8s.io/client-go/testing"
 )
 
 // roundTripFunc is a helper for creating a fake HTTP transport.
@@ -132,6 +135,285 @@ func TestValidate(t *testing.T) {
 			},
 			expectedError: "interactive mode is only supported for standard and wide table output",
 		},
+		{
+			name: "per-namespace with continue token",
+			opts: &HeadOptions{
+				Limit:         10,
+				PerNamespace:  true,
+				ContinueToken: "token",
+				PrintFlags:    genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--per-namespace cannot be used with --interactive or --continue",
+		},
+		{
+			name: "namespace selector without per-namespace",
+			opts: &HeadOptions{
+				Limit:             10,
+				NamespaceSelector: "team=a",
+				PrintFlags:        genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--namespace-selector can only be used with --per-namespace",
+		},
+		{
+			name: "contexts and all-contexts together",
+			opts: &HeadOptions{
+				Limit:       10,
+				Contexts:    []string{"a"},
+				AllContexts: true,
+				Concurrency: 1,
+				PrintFlags:  genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "cannot use --contexts and --all-contexts flags together",
+		},
+		{
+			name: "wide without csv or tsv output",
+			opts: &HeadOptions{
+				Limit:      10,
+				Wide:       true,
+				PrintFlags: genericclioptions.NewPrintFlags("").WithDefaultOutput("yaml"),
+			},
+			expectedError: "--wide can only be used with -o csv, tsv, markdown or html",
+		},
+		{
+			name: "resource version match without resource version",
+			opts: &HeadOptions{
+				Limit:                10,
+				ResourceVersionMatch: "NotOlderThan",
+				PrintFlags:           genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--resource-version-match requires --resource-version",
+		},
+		{
+			name: "invalid resource version match",
+			opts: &HeadOptions{
+				Limit:                10,
+				ResourceVersion:      "100",
+				ResourceVersionMatch: "Newest",
+				PrintFlags:           genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--resource-version-match must be one of: NotOlderThan, Exact",
+		},
+		{
+			name: "exact match at resource version 0",
+			opts: &HeadOptions{
+				Limit:                10,
+				ResourceVersion:      "0",
+				ResourceVersionMatch: "Exact",
+				PrintFlags:           genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--resource-version-match=Exact cannot be used with --resource-version=0",
+		},
+		{
+			name: "resource version with continue token",
+			opts: &HeadOptions{
+				Limit:           10,
+				ResourceVersion: "0",
+				ContinueToken:   "token",
+				PrintFlags:      genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "cannot use --resource-version with --continue; the continue token already selects a resource version",
+		},
+		{
+			name: "fan out without concurrency",
+			opts: &HeadOptions{
+				Limit:        10,
+				PerNamespace: true,
+				PrintFlags:   genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--concurrency must be a positive number",
+		},
+		{
+			name: "api version without a version",
+			opts: &HeadOptions{
+				Limit:      10,
+				APIVersion: "apps",
+				PrintFlags: genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--api-version must be of the form group/version, or version for the core group (e.g. apps/v1 or v1)",
+		},
+		{
+			name: "logs with json output",
+			opts: &HeadOptions{
+				Limit:       10,
+				Logs:        DefaultLogLines,
+				Concurrency: DefaultConcurrency,
+				PrintFlags:  genericclioptions.NewPrintFlags("").WithDefaultOutput("json"),
+			},
+			expectedError: "--logs can only be used with the default table output or -o wide",
+		},
+		{
+			name: "logs-first without logs",
+			opts: &HeadOptions{
+				Limit:      10,
+				LogsFirst:  true,
+				PrintFlags: genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--logs-first can only be used with --logs",
+		},
+		{
+			name: "unsupported subresource",
+			opts: &HeadOptions{
+				Limit:       10,
+				Subresource: "log",
+				Concurrency: DefaultConcurrency,
+				PrintFlags:  genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--subresource must be one of: status, scale",
+		},
+		{
+			name: "subresource with per-namespace",
+			opts: &HeadOptions{
+				Limit:        10,
+				Subresource:  "scale",
+				PerNamespace: true,
+				Concurrency:  DefaultConcurrency,
+				PrintFlags:   genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--subresource cannot be used with --per-namespace, --contexts or --all-contexts",
+		},
+		{
+			name: "events with yaml output",
+			opts: &HeadOptions{
+				Limit:       10,
+				Events:      true,
+				Concurrency: DefaultConcurrency,
+				PrintFlags:  genericclioptions.NewPrintFlags("").WithDefaultOutput("yaml"),
+			},
+			expectedError: "--events can only be used with table output: the default, -o wide, csv, tsv, markdown or html",
+		},
+		{
+			name: "events with subresource",
+			opts: &HeadOptions{
+				Limit:       10,
+				Events:      true,
+				Subresource: "status",
+				Concurrency: DefaultConcurrency,
+				PrintFlags:  genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--events cannot be used with --per-namespace, --contexts, --all-contexts or --subresource",
+		},
+		{
+			name: "top-owner without show-owner",
+			opts: &HeadOptions{
+				Limit:      10,
+				TopOwner:   true,
+				PrintFlags: genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--top-owner can only be used with --show-owner",
+		},
+		{
+			name: "show-owner with contexts",
+			opts: &HeadOptions{
+				Limit:       10,
+				ShowOwner:   true,
+				Contexts:    []string{"prod-east", "prod-west"},
+				Concurrency: DefaultConcurrency,
+				PrintFlags:  genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--show-owner cannot be used with --per-namespace, --contexts, --all-contexts or --subresource",
+		},
+		{
+			name: "with-metrics with json output",
+			opts: &HeadOptions{
+				Limit:       10,
+				WithMetrics: true,
+				Concurrency: DefaultConcurrency,
+				PrintFlags:  genericclioptions.NewPrintFlags("").WithDefaultOutput("json"),
+			},
+			expectedError: "--with-metrics can only be used with table output: the default, -o wide, csv, tsv, markdown or html",
+		},
+		{
+			name: "delete with all",
+			opts: &HeadOptions{
+				Limit:       10,
+				Delete:      true,
+				All:         true,
+				Concurrency: DefaultConcurrency,
+				PrintFlags:  genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--delete, --label and --annotate cannot be used with --all, --per-namespace, --contexts, --all-contexts or --subresource",
+		},
+		{
+			name: "delete with label",
+			opts: &HeadOptions{
+				Limit:       10,
+				Delete:      true,
+				Labels:      []string{"stuck=true"},
+				Concurrency: DefaultConcurrency,
+				PrintFlags:  genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--delete cannot be used with --label or --annotate",
+		},
+		{
+			name: "invalid label",
+			opts: &HeadOptions{
+				Limit:       10,
+				Labels:      []string{"stuck"},
+				Concurrency: DefaultConcurrency,
+				PrintFlags:  genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: `invalid --label "stuck": must be KEY=VALUE to set a key or KEY- to remove it`,
+		},
+		{
+			name: "unsupported dry run",
+			opts: &HeadOptions{
+				Limit:       10,
+				Delete:      true,
+				DryRun:      "client",
+				Concurrency: DefaultConcurrency,
+				PrintFlags:  genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--dry-run must be one of: none, server",
+		},
+		{
+			name: "dry run without action",
+			opts: &HeadOptions{
+				Limit:      10,
+				DryRun:     "server",
+				PrintFlags: genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--dry-run and --yes can only be used with --delete, --label or --annotate",
+		},
+		{
+			name: "max-pages without exec",
+			opts: &HeadOptions{
+				Limit:      10,
+				MaxPages:   5,
+				PrintFlags: genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--max-pages and --page-delay can only be used with --exec",
+		},
+		{
+			name: "exec with all",
+			opts: &HeadOptions{
+				Limit:      10,
+				Exec:       "xargs echo",
+				All:        true,
+				PrintFlags: genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--exec cannot be used with --interactive, --all, --per-namespace, --contexts, --all-contexts, --logs, --delete, --label or --annotate",
+		},
+		{
+			name: "exec names with events",
+			opts: &HeadOptions{
+				Limit:       10,
+				Exec:        "xargs echo",
+				Events:      true,
+				Concurrency: DefaultConcurrency,
+				PrintFlags:  genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--exec pipes names by default; use a table format such as -o tsv with --events, --show-owner or --with-metrics",
+		},
+		{
+			name: "exec names across namespaces",
+			opts: &HeadOptions{
+				Limit:         10,
+				Exec:          "xargs echo",
+				AllNamespaces: true,
+				PrintFlags:    genericclioptions.NewPrintFlags(""),
+			},
+			expectedError: "--exec pipes names without their namespaces by default; use an output format such as -o json with --all-namespaces",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -194,6 +476,55 @@ func TestRun(t *testing.T) {
 	}
 }
 
+func TestRun_ClusterScoped(t *testing.T) {
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+		Rows:              []metav1.TableRow{{Cells: []interface{}{"node-a"}}},
+	}
+	bodyBytes := mustMarshalJSON(table)
+
+	var path string
+	fakeRT := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(bodyBytes)),
+		}, nil
+	})
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:   "no",
+		Namespace:  "default",
+		Limit:      1,
+		RESTConfig: &rest.Config{},
+		Mapper:     newDiscoveryRESTMapper(t),
+		IOStreams:  streams,
+		PrintFlags: genericclioptions.NewPrintFlags(""),
+	}
+
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		config.Transport = fakeRT
+		config.GroupVersion = &gv
+		config.APIPath = "/api"
+		config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+		return rest.RESTClientFor(&config)
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	if path != "/api/v1/nodes" {
+		t.Errorf("expected nodes to be listed without a namespace, got path %q", path)
+	}
+	if !strings.Contains(out.String(), "node-a") {
+		t.Errorf("expected output to contain %q, but got %q", "node-a", out.String())
+	}
+}
+
 func TestRun_WithContinue(t *testing.T) {
 	table := &metav1.Table{
 		TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
@@ -274,8 +605,7 @@ func TestNewRestClient(t *testing.T) {
 }
 
 func TestGetResourceGVR(t *testing.T) {
-	streams := genericclioptions.NewTestIOStreamsDiscard()
-	opts := NewHeadOptions(streams)
+	opts := &HeadOptions{IOStreams: genericclioptions.NewTestIOStreamsDiscard()}
 
 	testCases := []struct {
 		name          string
@@ -288,7 +618,8 @@ func TestGetResourceGVR(t *testing.T) {
 			name:        "simple resource",
 			resourceArg: "pods",
 			mapper: &fakeRESTMapperImpl{
-				gvr: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"},
+				gvr:  schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"},
+				kind: "Pod",
 			},
 			expectedGVR: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"},
 		},
@@ -296,18 +627,96 @@ func TestGetResourceGVR(t *testing.T) {
 			name:        "resource with group",
 			resourceArg: "deployments.apps",
 			mapper: &fakeRESTMapperImpl{
-				gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+				gvr:  schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+				kind: "Deployment",
+			},
+			expectedGVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		{
+			name:        "resource with version and group",
+			resourceArg: "deployments.v1.apps",
+			mapper: &fakeRESTMapperImpl{
+				gvr:  schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+				kind: "Deployment",
+			},
+			expectedGVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		{
+			name:        "kind",
+			resourceArg: "Deployment",
+			mapper: &fakeRESTMapperImpl{
+				gvr:  schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+				kind: "Deployment",
 			},
 			expectedGVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
 		},
+		{
+			name:        "kind with group",
+			resourceArg: "Deployment.apps",
+			mapper: &fakeRESTMapperImpl{
+				gvr:  schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+				kind: "Deployment",
+			},
+			expectedGVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		{
+			name:        "short name",
+			resourceArg: "deploy",
+			mapper:      newDiscoveryRESTMapper(t),
+			expectedGVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		{
+			name:        "singular resource",
+			resourceArg: "deployment",
+			mapper:      newDiscoveryRESTMapper(t),
+			expectedGVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		{
+			name:        "kind from discovery",
+			resourceArg: "Deployment",
+			mapper:      newDiscoveryRESTMapper(t),
+			expectedGVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		{
+			name:        "group with dots",
+			resourceArg: "ingresses.networking.k8s.io",
+			mapper:      newDiscoveryRESTMapper(t),
+			expectedGVR: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+		},
+		{
+			name:        "version and group with dots",
+			resourceArg: "ingresses.v1.networking.k8s.io",
+			mapper:      newDiscoveryRESTMapper(t),
+			expectedGVR: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+		},
+		{
+			name:        "short name with group",
+			resourceArg: "ing.networking.k8s.io",
+			mapper:      newDiscoveryRESTMapper(t),
+			expectedGVR: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+		},
+		{
+			name:          "resource in the wrong group",
+			resourceArg:   "deployments.batch",
+			mapper:        newDiscoveryRESTMapper(t),
+			expectedError: `the server doesn't have a resource type "deployments.batch"`,
+		},
 		{
 			name:        "resource not found",
 			resourceArg: "nonexistent",
 			mapper: &fakeRESTMapperImpl{
-				err: errors.New("not found"),
+				err: &meta.NoResourceMatchError{PartialResource: schema.GroupVersionResource{Resource: "nonexistent"}},
 			},
 			expectedError: `the server doesn't have a resource type "nonexistent"`,
 		},
+		{
+			name:        "discovery error",
+			resourceArg: "pods",
+			mapper: &fakeRESTMapperImpl{
+				err: errors.New("connection refused"),
+			},
+			expectedError: "connection refused",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -335,14 +744,77 @@ func TestGetResourceGVR(t *testing.T) {
 // --- Test Helpers ---
 
 type fakeRESTMapperImpl struct {
-	gvr schema.GroupVersionResource
-	err error
+	gvr           schema.GroupVersionResource
+	kind          string
+	clusterScoped bool
+	err           error
 }
 
 func fakeRESTMapper() meta.RESTMapper {
 	return &fakeRESTMapperImpl{
-		gvr: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"},
+		gvr:  schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"},
+		kind: "Pod",
+	}
+}
+
+// newDiscoveryRESTMapper returns a RESTMapper with short names expanded, as
+// created by the ConfigFlags, for a cluster with pods, nodes, deployments,
+// replicasets, ingresses, widgets served in v1 and v1beta1, and events in both
+// the core and events.k8s.io groups.
+func newDiscoveryRESTMapper(t *testing.T) meta.RESTMapper {
+	client := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", SingularName: "pod", Namespaced: true, Kind: "Pod", ShortNames: []string{"po"}, Verbs: metav1.Verbs{"list"}},
+				{Name: "nodes", SingularName: "node", Kind: "Node", ShortNames: []string{"no"}, Verbs: metav1.Verbs{"list"}},
+				{Name: "events", SingularName: "event", Namespaced: true, Kind: "Event", ShortNames: []string{"ev"}, Verbs: metav1.Verbs{"list"}},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", SingularName: "widget", Namespaced: true, Kind: "Widget", Verbs: metav1.Verbs{"list"}},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", SingularName: "widget", Namespaced: true, Kind: "Widget", Verbs: metav1.Verbs{"list"}},
+			},
+		},
+		{
+			GroupVersion: "events.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "events", SingularName: "event", Namespaced: true, Kind: "Event", ShortNames: []string{"ev"}, Verbs: metav1.Verbs{"list"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", SingularName: "deployment", Namespaced: true, Kind: "Deployment", ShortNames: []string{"deploy"}, Verbs: metav1.Verbs{"list"}},
+				{Name: "replicasets", SingularName: "replicaset", Namespaced: true, Kind: "ReplicaSet", ShortNames: []string{"rs"}, Verbs: metav1.Verbs{"list"}},
+			},
+		},
+		{
+			GroupVersion: "metrics.k8s.io/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Namespaced: true, Kind: "PodMetrics", Verbs: metav1.Verbs{"get", "list"}},
+				{Name: "nodes", Kind: "NodeMetrics", Verbs: metav1.Verbs{"get", "list"}},
+			},
+		},
+		{
+			GroupVersion: "networking.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "ingresses", SingularName: "ingress", Namespaced: true, Kind: "Ingress", ShortNames: []string{"ing"}, Verbs: metav1.Verbs{"list"}},
+			},
+		},
+	}}}
+	groupResources, err := restmapper.GetAPIGroupResources(client)
+	if err != nil {
+		t.Fatalf("failed to discover resources: %v", err)
 	}
+	return restmapper.NewShortcutExpander(restmapper.NewDiscoveryRESTMapper(groupResources), client, nil)
 }
 
 func (f *fakeRESTMapperImpl) ResourceFor(input schema.GroupVersionResource) (schema.GroupVersionResource, error) {
@@ -356,7 +828,15 @@ func (f *fakeRESTMapperImpl) ResourceFor(input schema.GroupVersionResource) (sch
 }
 
 func (f *fakeRESTMapperImpl) KindFor(resource schema.GroupVersionResource) (schema.GroupVersionKind, error) {
-	return schema.GroupVersionKind{}, fmt.Errorf("not implemented")
+	if f.err != nil {
+		return schema.GroupVersionKind{}, f.err
+	}
+	if f.kind == "" || resource.Resource != f.gvr.Resource ||
+		(resource.Group != "" && resource.Group != f.gvr.Group) ||
+		(resource.Version != "" && resource.Version != f.gvr.Version) {
+		return schema.GroupVersionKind{}, &meta.NoResourceMatchError{PartialResource: resource}
+	}
+	return f.gvr.GroupVersion().WithKind(f.kind), nil
 }
 func (f *fakeRESTMapperImpl) KindsFor(resource schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
 	return nil, fmt.Errorf("not implemented")
@@ -365,7 +845,22 @@ func (f *fakeRESTMapperImpl) ResourcesFor(input schema.GroupVersionResource) ([]
 	return nil, fmt.Errorf("not implemented")
 }
 func (f *fakeRESTMapperImpl) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
-	return nil, fmt.Errorf("not implemented")
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.kind == "" || gk.Kind != f.kind || (gk.Group != "" && gk.Group != f.gvr.Group) ||
+		(len(versions) > 0 && versions[0] != "" && versions[0] != f.gvr.Version) {
+		return nil, &meta.NoKindMatchError{GroupKind: gk, SearchedVersions: versions}
+	}
+	scope := meta.RESTScopeNamespace
+	if f.clusterScoped {
+		scope = meta.RESTScopeRoot
+	}
+	return &meta.RESTMapping{
+		Resource:         f.gvr,
+		GroupVersionKind: f.gvr.GroupVersion().WithKind(f.kind),
+		Scope:            scope,
+	}, nil
 }
 func (f *fakeRESTMapperImpl) RESTMappings(gk schema.GroupKind, versions ...string) ([]*meta.RESTMapping, error) {
 	return nil, fmt.Errorf("not implemented")
@@ -0,0 +1,107 @@
+package head
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// DefaultLogLines is the number of log lines shown for each container by
+// --logs without a value.
+const DefaultLogLines int64 = 10
+
+var podsGVR = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+
+// containerLogs is the logs of a single container, or the error fetching them.
+type containerLogs struct {
+	prefix string
+	lines  []string
+	err    error
+}
+
+// printLogs prints to w the first or last Logs lines of the logs of each
+// container of the pods in a page, prefixed with the pod and container names.
+// Logs are fetched concurrently, with at most Concurrency requests in flight,
+// and printed in the order of the page. Containers whose logs can't be fetched,
+// such as those still waiting to start, are reported as warnings on ErrOut.
+func (o *HeadOptions) printLogs(table *metav1.Table, w io.Writer) error {
+	client, err := kubernetes.NewForConfig(o.RESTConfig)
+	if err != nil {
+		return err
+	}
+
+	var pods []*corev1.Pod
+	for _, row := range table.Rows {
+		obj, err := rowObject(row)
+		if err != nil {
+			return err
+		}
+		pod := &corev1.Pod{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, pod); err != nil {
+			return err
+		}
+		pods = append(pods, pod)
+	}
+
+	logs := make([][]containerLogs, len(pods))
+	runParallel(len(pods), o.Concurrency, func(i int) {
+		pod := pods[i]
+		podClient := client.CoreV1().Pods(pod.Namespace)
+		for _, container := range pod.Spec.Containers {
+			lines, err := o.fetchLogs(podClient, pod.Name, container.Name)
+			logs[i] = append(logs[i], containerLogs{
+				prefix: fmt.Sprintf("[pod/%s/%s]", pod.Name, container.Name),
+				lines:  lines,
+				err:    err,
+			})
+		}
+	})
+
+	printedHeader := false
+	for _, podLogs := range logs {
+		for _, container := range podLogs {
+			if container.err != nil {
+				fmt.Fprintf(o.ErrOut, "Warning: %s %v\n", container.prefix, container.err)
+				continue
+			}
+			for _, line := range container.lines {
+				if !printedHeader {
+					fmt.Fprintln(w)
+					printedHeader = true
+				}
+				fmt.Fprintf(w, "%s %s\n", container.prefix, line)
+			}
+		}
+	}
+	return nil
+}
+
+// fetchLogs returns the first or last Logs lines of the logs of a container.
+// The server can only limit the log to its last lines, so for the first lines
+// the log is streamed and closed once enough lines have been read.
+func (o *HeadOptions) fetchLogs(pods corev1client.PodInterface, pod, container string) ([]string, error) {
+	logOptions := &corev1.PodLogOptions{Container: container}
+	if !o.LogsFirst {
+		logOptions.TailLines = &o.Logs
+	}
+	stream, err := pods.GetLogs(pod, logOptions).Stream(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(stream)
+	for int64(len(lines)) < o.Logs && scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,177 @@
+package head
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+// newLogServer returns a server that lists pod-a, with containers app and
+// sidecar, and pod-b, whose app container is waiting to start. Each log has
+// five lines, or the tailLines requested.
+func newLogServer(t *testing.T, logQueries *[]string) *httptest.Server {
+	pod := func(name string, containers ...string) runtime.RawExtension {
+		var specs []string
+		for _, container := range containers {
+			specs = append(specs, fmt.Sprintf(`{"name":%q,"image":"busybox"}`, container))
+		}
+		return runtime.RawExtension{Raw: []byte(fmt.Sprintf(
+			`{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"default","name":%q},"spec":{"containers":[%s]}}`,
+			name, strings.Join(specs, ",")))}
+	}
+	table := &metav1.Table{
+		TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+		Rows: []metav1.TableRow{
+			{Cells: []interface{}{"pod-a"}, Object: pod("pod-a", "app", "sidecar")},
+			{Cells: []interface{}{"pod-b"}, Object: pod("pod-b", "app")},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v1/namespaces/default/pods":
+			if req.URL.Query().Get("includeObject") != string(metav1.IncludeObject) {
+				t.Errorf("expected the list to include the pods, got query %q", req.URL.RawQuery)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(table); err != nil {
+				t.Errorf("failed to encode response: %v", err)
+			}
+		case "/api/v1/namespaces/default/pods/pod-a/log":
+			*logQueries = append(*logQueries, req.URL.RawQuery)
+			container := req.URL.Query().Get("container")
+			lines := 5
+			if tail := req.URL.Query().Get("tailLines"); tail != "" {
+				fmt.Sscanf(tail, "%d", &lines)
+			}
+			for i := 5 - lines; i < 5; i++ {
+				fmt.Fprintf(w, "%s line %d\n", container, i)
+			}
+		case "/api/v1/namespaces/default/pods/pod-b/log":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","message":"container \"app\" in pod \"pod-b\" is waiting to start: ContainerCreating","reason":"BadRequest","code":400}`)
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRun_Logs(t *testing.T) {
+	testCases := []struct {
+		name            string
+		first           bool
+		expectedQueries []string
+		expectedLogs    string
+	}{
+		{
+			name:            "last lines",
+			expectedQueries: []string{"container=app&tailLines=2", "container=sidecar&tailLines=2"},
+			expectedLogs: `[pod/pod-a/app] app line 3
+[pod/pod-a/app] app line 4
+[pod/pod-a/sidecar] sidecar line 3
+[pod/pod-a/sidecar] sidecar line 4
+`,
+		},
+		{
+			name:            "first lines",
+			first:           true,
+			expectedQueries: []string{"container=app", "container=sidecar"},
+			expectedLogs: `[pod/pod-a/app] app line 0
+[pod/pod-a/app] app line 1
+[pod/pod-a/sidecar] sidecar line 0
+[pod/pod-a/sidecar] sidecar line 1
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var queries []string
+			server := newLogServer(t, &queries)
+
+			streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+			opts := &HeadOptions{
+				Resource:    "pods",
+				Namespace:   "default",
+				Limit:       2,
+				Logs:        2,
+				LogsFirst:   tc.first,
+				Concurrency: 1,
+				RESTConfig:  &rest.Config{Host: server.URL},
+				Mapper:      fakeRESTMapper(),
+				IOStreams:   streams,
+				PrintFlags:  genericclioptions.NewPrintFlags(""),
+			}
+			if err := opts.Validate(); err != nil {
+				t.Fatalf("unexpected error during Validate: %v", err)
+			}
+			if err := opts.Run(); err != nil {
+				t.Fatalf("unexpected error during Run: %v", err)
+			}
+
+			if fmt.Sprint(queries) != fmt.Sprint(tc.expectedQueries) {
+				t.Errorf("expected log requests %v, got %v", tc.expectedQueries, queries)
+			}
+			if !strings.Contains(out.String(), "pod-b\n\n"+tc.expectedLogs) {
+				t.Errorf("expected the logs after the table:\n%s\ngot:\n%s", tc.expectedLogs, out.String())
+			}
+			if !strings.Contains(errOut.String(), `Warning: [pod/pod-b/app] container "app" in pod "pod-b" is waiting to start`) {
+				t.Errorf("expected a warning for the waiting container, got %q", errOut.String())
+			}
+		})
+	}
+}
+
+func TestRun_AllLogsToFile(t *testing.T) {
+	var queries []string
+	server := newLogServer(t, &queries)
+	outputFile := filepath.Join(t.TempDir(), "pods.txt")
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:    "pods",
+		Namespace:   "default",
+		Limit:       2,
+		Logs:        1,
+		All:         true,
+		OutputFile:  outputFile,
+		Concurrency: 1,
+		RESTConfig:  &rest.Config{Host: server.URL},
+		Mapper:      fakeRESTMapper(),
+		IOStreams:   streams,
+		PrintFlags:  genericclioptions.NewPrintFlags(""),
+	}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("unexpected error during Validate: %v", err)
+	}
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	// The logs follow the rows of their page in the file.
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	expected := "pod-b\n\n[pod/pod-a/app] app line 4\n[pod/pod-a/sidecar] sidecar line 4\n"
+	if !strings.Contains(string(data), expected) {
+		t.Errorf("expected the file to contain:\n%s\ngot:\n%s", expected, data)
+	}
+	if out.Len() > 0 {
+		t.Errorf("expected nothing on stdout, got:\n%s", out.String())
+	}
+}
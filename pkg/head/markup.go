@@ -0,0 +1,139 @@
+package head
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"text/template"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// markdownPagePrinter prints the table rows of each page as a GitHub-flavored
+// Markdown table, with the header before the first page.
+type markdownPagePrinter struct {
+	wide          bool
+	link          *template.Template
+	printedHeader bool
+}
+
+func (p *markdownPagePrinter) PrintPage(table *metav1.Table, w io.Writer) error {
+	columns := visibleColumns(table, p.wide)
+	nameColumn := nameColumnIndex(table, columns)
+
+	if !p.printedHeader && len(table.Rows) > 0 {
+		var header, separator strings.Builder
+		for _, column := range columns {
+			fmt.Fprintf(&header, "| %s ", escapeMarkdown(table.ColumnDefinitions[column].Name))
+			separator.WriteString("| --- ")
+		}
+		if _, err := fmt.Fprintf(w, "%s|\n%s|\n", header.String(), separator.String()); err != nil {
+			return err
+		}
+		p.printedHeader = true
+	}
+	for _, row := range table.Rows {
+		url, err := linkFor(p.link, row)
+		if err != nil {
+			return err
+		}
+		var line strings.Builder
+		for _, column := range columns {
+			cell := escapeMarkdown(cellString(row, column))
+			if column == nameColumn && url != "" {
+				cell = fmt.Sprintf("[%s](%s)", cell, url)
+			}
+			fmt.Fprintf(&line, "| %s ", cell)
+		}
+		if _, err := fmt.Fprintf(w, "%s|\n", line.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeMarkdown keeps a cell value from breaking out of its table cell.
+func escapeMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.NewReplacer("\r\n", "<br>", "\n", "<br>").Replace(s)
+}
+
+// htmlPagePrinter prints the table rows of each page as rows of a single HTML
+// table, which is closed by Finish.
+type htmlPagePrinter struct {
+	wide   bool
+	link   *template.Template
+	opened bool
+}
+
+func (p *htmlPagePrinter) PrintPage(table *metav1.Table, w io.Writer) error {
+	columns := visibleColumns(table, p.wide)
+	nameColumn := nameColumnIndex(table, columns)
+
+	var b strings.Builder
+	if !p.opened && len(table.Rows) > 0 {
+		b.WriteString("<table>\n<thead>\n<tr>")
+		for _, column := range columns {
+			fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(table.ColumnDefinitions[column].Name))
+		}
+		b.WriteString("</tr>\n</thead>\n<tbody>\n")
+		p.opened = true
+	}
+	for _, row := range table.Rows {
+		url, err := linkFor(p.link, row)
+		if err != nil {
+			return err
+		}
+		b.WriteString("<tr>")
+		for _, column := range columns {
+			cell := html.EscapeString(cellString(row, column))
+			if column == nameColumn && url != "" {
+				cell = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), cell)
+			}
+			fmt.Fprintf(&b, "<td>%s</td>", cell)
+		}
+		b.WriteString("</tr>\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (p *htmlPagePrinter) Finish(w io.Writer) error {
+	if !p.opened {
+		return nil
+	}
+	_, err := io.WriteString(w, "</tbody>\n</table>\n")
+	return err
+}
+
+// nameColumnIndex returns the column holding the object name, which is where
+// links are placed. It falls back to the first visible column.
+func nameColumnIndex(table *metav1.Table, columns []int) int {
+	for _, column := range columns {
+		if table.ColumnDefinitions[column].Format == "name" {
+			return column
+		}
+	}
+	if len(columns) == 0 {
+		return -1
+	}
+	return columns[0]
+}
+
+// linkFor executes the link template against the object embedded in the row.
+// It returns "" if there is no template or the row has no object.
+func linkFor(link *template.Template, row metav1.TableRow) (string, error) {
+	if link == nil || len(row.Object.Raw) == 0 {
+		return "", nil
+	}
+	obj, err := rowObject(row)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := link.Execute(&b, obj.Object); err != nil {
+		return "", fmt.Errorf("error executing --link-template: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,88 @@
+package head
+
+import (
+	"bytes"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func newMarkupTestTable() *metav1.Table {
+	return &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Format: "name"},
+			{Name: "Status"},
+			{Name: "IP", Priority: 1},
+		},
+		Rows: []metav1.TableRow{{
+			Cells:  []interface{}{"pod-a", "Error|<OOM>", "10.0.0.1"},
+			Object: rawPod("team-a", "pod-a"),
+		}},
+	}
+}
+
+func rawPod(namespace, name string) runtime.RawExtension {
+	return runtime.RawExtension{
+		Raw: []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"` + namespace + `","name":"` + name + `"}}`),
+	}
+}
+
+func TestMarkupPagePrinters(t *testing.T) {
+	testCases := []struct {
+		name     string
+		format   string
+		wide     bool
+		link     string
+		expected string
+	}{
+		{
+			name:   "markdown",
+			format: "markdown",
+			expected: "| Name | Status |\n| --- | --- |\n" +
+				"| pod-a | Error\\|<OOM> |\n",
+		},
+		{
+			name:   "markdown wide with links",
+			format: "markdown",
+			wide:   true,
+			link:   "https://dash/{{.metadata.namespace}}/{{.metadata.name}}",
+			expected: "| Name | Status | IP |\n| --- | --- | --- |\n" +
+				"| [pod-a](https://dash/team-a/pod-a) | Error\\|<OOM> | 10.0.0.1 |\n",
+		},
+		{
+			name:   "html with links",
+			format: "html",
+			link:   "https://dash/?ns={{.metadata.namespace}}&name={{.metadata.name}}",
+			expected: "<table>\n<thead>\n<tr><th>Name</th><th>Status</th></tr>\n</thead>\n<tbody>\n" +
+				`<tr><td><a href="https://dash/?ns=team-a&amp;name=pod-a">pod-a</a></td><td>Error|&lt;OOM&gt;</td></tr>` + "\n" +
+				"</tbody>\n</table>\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &HeadOptions{
+				Wide:         tc.wide,
+				LinkTemplate: tc.link,
+				PrintFlags:   genericclioptions.NewPrintFlags("").WithDefaultOutput(tc.format),
+			}
+			printer, err := opts.newPagePrinter()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var out bytes.Buffer
+			if err := printer.PrintPage(newMarkupTestTable(), &out); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := finishPrinting(printer, &out); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package head
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var nodesGVR = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+
+// metricsGVRs maps the resources supported by --with-metrics to their
+// resource metrics in the metrics.k8s.io API.
+var metricsGVRs = map[schema.GroupResource]schema.GroupVersionResource{
+	podsGVR.GroupResource():  {Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"},
+	nodesGVR.GroupResource(): {Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"},
+}
+
+// metricsColumns are the columns added to each page by --with-metrics, named
+// like the columns of kubectl top.
+var metricsColumns = []metav1.TableColumnDefinition{
+	{Name: "CPU(cores)", Type: "string", Description: "The CPU usage of the object, in millicores."},
+	{Name: "Memory(bytes)", Type: "string", Description: "The memory usage of the object, in mebibytes."},
+}
+
+// usage is the resource usage of an object, or the error fetching it.
+type usage struct {
+	cpu, memory resource.Quantity
+	err         error
+}
+
+// addMetrics adds CPU and memory columns to the page with the usage of each
+// pod or node, fetched by name from metrics.k8s.io with at most Concurrency
+// requests in flight. Only the objects on the page are fetched, rather than the
+// metrics of the whole cluster. Objects without metrics, such as pods that
+// just started, show <unknown>; other errors are also reported as warnings on
+// ErrOut.
+func (o *HeadOptions) addMetrics(table *metav1.Table, gvr schema.GroupVersionResource) error {
+	metricsGVR := metricsGVRs[gvr.GroupResource()]
+
+	objs := make([]*unstructured.Unstructured, len(table.Rows))
+	for i, row := range table.Rows {
+		obj, err := rowObject(row)
+		if err != nil {
+			return err
+		}
+		objs[i] = obj
+	}
+
+	usages := make([]usage, len(objs))
+	runParallel(len(objs), o.Concurrency, func(i int) {
+		metrics, err := o.DynamicClient.Resource(metricsGVR).Namespace(objs[i].GetNamespace()).Get(context.Background(), objs[i].GetName(), metav1.GetOptions{})
+		if err != nil {
+			usages[i].err = err
+			return
+		}
+		usages[i] = sumUsage(metrics)
+	})
+
+	table.ColumnDefinitions = append(table.ColumnDefinitions, metricsColumns...)
+	for i := range table.Rows {
+		cpu, memory := "<unknown>", "<unknown>"
+		if err := usages[i].err; err == nil {
+			cpu = fmt.Sprintf("%dm", usages[i].cpu.MilliValue())
+			memory = fmt.Sprintf("%dMi", usages[i].memory.Value()/(1024*1024))
+		} else if !apierrors.IsNotFound(err) {
+			fmt.Fprintf(o.ErrOut, "Warning: cannot get metrics for %s %q: %v\n", gvr.Resource, objs[i].GetName(), err)
+		}
+		table.Rows[i].Cells = append(table.Rows[i].Cells, cpu, memory)
+	}
+	return nil
+}
+
+// sumUsage returns the usage of a NodeMetrics, or the total usage of the
+// containers of a PodMetrics.
+func sumUsage(metrics *unstructured.Unstructured) usage {
+	var total usage
+	add := func(fields map[string]interface{}) {
+		for name, q := range map[string]*resource.Quantity{"cpu": &total.cpu, "memory": &total.memory} {
+			value, _, _ := unstructured.NestedString(fields, "usage", name)
+			if parsed, err := resource.ParseQuantity(value); err == nil {
+				q.Add(parsed)
+			}
+		}
+	}
+	if containers, ok, _ := unstructured.NestedSlice(metrics.Object, "containers"); ok {
+		for _, container := range containers {
+			if fields, ok := container.(map[string]interface{}); ok {
+				add(fields)
+			}
+		}
+		return total
+	}
+	add(metrics.Object)
+	return total
+}
@@ -0,0 +1,129 @@
+package head
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func newPodMetrics(name string, usages ...map[string]interface{}) *unstructured.Unstructured {
+	var containers []interface{}
+	for i, usage := range usages {
+		containers = append(containers, map[string]interface{}{"name": fmt.Sprintf("c%d", i), "usage": usage})
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": name},
+		"containers": containers,
+	}}
+}
+
+func TestRun_WithMetrics(t *testing.T) {
+	row := func(name string) metav1.TableRow {
+		return metav1.TableRow{
+			Cells: []interface{}{name},
+			Object: runtime.RawExtension{Raw: []byte(fmt.Sprintf(
+				`{"apiVersion":"meta.k8s.io/v1","kind":"PartialObjectMetadata","metadata":{"namespace":"default","name":%q}}`, name))},
+		}
+	}
+	table := &metav1.Table{
+		TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+		Rows:              []metav1.TableRow{row("pod-a"), row("pod-b"), row("pod-c")},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/v1/namespaces/default/pods" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(table); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	// pod-a has two containers, pod-b has no metrics yet and the metrics of
+	// pod-c can't be read.
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	metricsGVR := metricsGVRs[podsGVR.GroupResource()]
+	podA := newPodMetrics("pod-a",
+		map[string]interface{}{"cpu": "100m", "memory": "64Mi"},
+		map[string]interface{}{"cpu": "150000000n", "memory": "32768Ki"})
+	if err := dynamicClient.Tracker().Create(metricsGVR, podA, "default"); err != nil {
+		t.Fatalf("failed to create pod metrics: %v", err)
+	}
+	dynamicClient.PrependReactor("get", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.(clienttesting.GetAction).GetName() != "pod-c" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(metricsGVR.GroupResource(), "pod-c", fmt.Errorf("access denied"))
+	})
+
+	streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:      "pods",
+		Namespace:     "default",
+		Limit:         3,
+		WithMetrics:   true,
+		Concurrency:   2,
+		RESTConfig:    &rest.Config{Host: server.URL},
+		Mapper:        fakeRESTMapper(),
+		DynamicClient: dynamicClient,
+		IOStreams:     streams,
+		PrintFlags:    genericclioptions.NewPrintFlags("").WithDefaultOutput("tsv"),
+	}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("unexpected error during Validate: %v", err)
+	}
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	expected := `Name	CPU(cores)	Memory(bytes)
+pod-a	250m	96Mi
+pod-b	<unknown>	<unknown>
+pod-c	<unknown>	<unknown>
+`
+	if !strings.HasPrefix(out.String(), expected) {
+		t.Errorf("expected output to start with:\n%s\ngot:\n%s", expected, out.String())
+	}
+	expectedErrOut := `Warning: cannot get metrics for pods "pod-c": pods.metrics.k8s.io "pod-c" is forbidden: access denied`
+	if !strings.Contains(errOut.String(), expectedErrOut) {
+		t.Errorf("expected stderr to contain %q, got %q", expectedErrOut, errOut.String())
+	}
+	if strings.Contains(errOut.String(), "pod-b") {
+		t.Errorf("expected no warning for pods without metrics, got %q", errOut.String())
+	}
+}
+
+func TestRun_WithMetricsUnsupportedResource(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:    "deployments",
+		Limit:       10,
+		WithMetrics: true,
+		Concurrency: DefaultConcurrency,
+		RESTConfig:  &rest.Config{},
+		Mapper:      newDiscoveryRESTMapper(t),
+		IOStreams:   streams,
+		PrintFlags:  genericclioptions.NewPrintFlags(""),
+	}
+	err := opts.Run()
+	if err == nil || err.Error() != "--with-metrics can only be used with pods or nodes" {
+		t.Errorf("expected an error for deployments, got %v", err)
+	}
+}
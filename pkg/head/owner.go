@@ -0,0 +1,144 @@
+package head
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/metadata"
+)
+
+// ownerColumn is the column added to each page by --show-owner.
+var ownerColumn = metav1.TableColumnDefinition{
+	Name:        "Owner",
+	Type:        "string",
+	Description: "The controller that owns the object, as Kind/Name.",
+}
+
+// owner identifies an object referenced by an owner reference. Owners of
+// cluster-scoped kinds are identified with an empty namespace.
+type owner struct {
+	namespace  string
+	apiVersion string
+	kind       string
+	name       string
+}
+
+func newOwner(namespace string, ref *metav1.OwnerReference) owner {
+	return owner{namespace: namespace, apiVersion: ref.APIVersion, kind: ref.Kind, name: ref.Name}
+}
+
+func (w owner) String() string {
+	return w.kind + "/" + w.name
+}
+
+// ownerResolver walks owner references up to the top-level owner, caching the
+// owner of each object it looks up, since the rows of a list usually share
+// their owners.
+type ownerResolver struct {
+	client metadata.Interface
+	mapper meta.RESTMapper
+	errOut io.Writer
+	owners map[owner]*owner
+}
+
+// addOwners adds a column to the page with the controller of each object, or
+// its first owner if none is marked as the controller. If TopOwner is set, the
+// owners are followed up to the top-level owner, e.g. from a pod to its
+// Deployment rather than its ReplicaSet. Owners that can't be looked up are
+// reported as warnings on ErrOut and the walk stops at the last known owner.
+func (o *HeadOptions) addOwners(table *metav1.Table) error {
+	if o.TopOwner && o.ownerResolver == nil {
+		client, err := metadata.NewForConfig(o.RESTConfig)
+		if err != nil {
+			return err
+		}
+		o.ownerResolver = &ownerResolver{client: client, mapper: o.Mapper, errOut: o.ErrOut, owners: map[owner]*owner{}}
+	}
+
+	table.ColumnDefinitions = append(table.ColumnDefinitions, ownerColumn)
+	for i, row := range table.Rows {
+		obj, err := rowObject(row)
+		if err != nil {
+			return err
+		}
+		cell := "<none>"
+		if ref := controllerOf(obj.GetOwnerReferences()); ref != nil {
+			current := newOwner(obj.GetNamespace(), ref)
+			if o.TopOwner {
+				current = o.ownerResolver.topOwner(current)
+			}
+			cell = current.String()
+		}
+		table.Rows[i].Cells = append(table.Rows[i].Cells, cell)
+	}
+	return nil
+}
+
+// controllerOf returns the owner reference marked as the controller, or the
+// first owner reference if none is, or nil for an object without owners.
+func controllerOf(refs []metav1.OwnerReference) *metav1.OwnerReference {
+	if ref := metav1.GetControllerOfNoCopy(&metav1.ObjectMeta{OwnerReferences: refs}); ref != nil {
+		return ref
+	}
+	if len(refs) > 0 {
+		return &refs[0]
+	}
+	return nil
+}
+
+// topOwner follows the owners of current until an object without owners. An
+// owner that can't be looked up, e.g. because it was deleted or can't be read,
+// is reported once and treated as the top-level owner. Visited owners are
+// tracked to stop on cycles, which the garbage collector leaves in place.
+func (r *ownerResolver) topOwner(current owner) owner {
+	visited := map[owner]bool{}
+	for !visited[current] {
+		visited[current] = true
+		next, err := r.ownerOf(current)
+		if err != nil {
+			fmt.Fprintf(r.errOut, "Warning: cannot look up owner %s: %v\n", current, err)
+			r.owners[current] = nil
+		}
+		if next == nil {
+			break
+		}
+		current = *next
+	}
+	return current
+}
+
+// ownerOf returns the owner of the object identified by w, or nil if it has
+// none.
+func (r *ownerResolver) ownerOf(w owner) (*owner, error) {
+	if next, ok := r.owners[w]; ok {
+		return next, nil
+	}
+	gv, err := schema.ParseGroupVersion(w.apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := r.mapper.RESTMapping(schema.GroupKind{Group: gv.Group, Kind: w.kind}, gv.Version)
+	if err != nil {
+		return nil, err
+	}
+	namespace := w.namespace
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		namespace = ""
+	}
+	obj, err := r.client.Resource(mapping.Resource).Namespace(namespace).Get(context.Background(), w.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var next *owner
+	if ref := controllerOf(obj.OwnerReferences); ref != nil {
+		parent := newOwner(namespace, ref)
+		next = &parent
+	}
+	r.owners[w] = next
+	return next, nil
+}
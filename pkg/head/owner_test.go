@@ -0,0 +1,151 @@
+package head
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+// newOwnerServer returns a server that lists pods owned by the web-abc
+// ReplicaSet of the web Deployment, a mirror pod owned by a node, a pod
+// without owners and a pod whose ReplicaSet was deleted. The paths of the
+// owners looked up are appended to lookups.
+func newOwnerServer(t *testing.T, lookups *[]string) *httptest.Server {
+	ownerRef := func(apiVersion, kind, name string, controller bool) string {
+		return fmt.Sprintf(`{"apiVersion":%q,"kind":%q,"name":%q,"uid":"uid-%s","controller":%t}`, apiVersion, kind, name, name, controller)
+	}
+	metadata := func(namespace, name string, refs ...string) string {
+		return fmt.Sprintf(`{"apiVersion":"meta.k8s.io/v1","kind":"PartialObjectMetadata","metadata":{"namespace":%q,"name":%q,"ownerReferences":[%s]}}`,
+			namespace, name, strings.Join(refs, ","))
+	}
+	row := func(name string, refs ...string) metav1.TableRow {
+		return metav1.TableRow{Cells: []interface{}{name}, Object: runtime.RawExtension{Raw: []byte(metadata("default", name, refs...))}}
+	}
+	table := &metav1.Table{
+		TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+		Rows: []metav1.TableRow{
+			row("web-abc-1", ownerRef("v1", "Node", "node-1", false), ownerRef("apps/v1", "ReplicaSet", "web-abc", true)),
+			row("web-abc-2", ownerRef("apps/v1", "ReplicaSet", "web-abc", true)),
+			row("kube-proxy-node-1", ownerRef("v1", "Node", "node-1", true)),
+			row("standalone"),
+			row("orphan-1", ownerRef("apps/v1", "ReplicaSet", "gone", true)),
+		},
+	}
+	owners := map[string]string{
+		"/apis/apps/v1/namespaces/default/replicasets/web-abc": metadata("default", "web-abc", ownerRef("apps/v1", "Deployment", "web", true)),
+		"/apis/apps/v1/namespaces/default/deployments/web":     metadata("default", "web"),
+		"/api/v1/nodes/node-1":                                 metadata("", "node-1"),
+	}
+
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if req.URL.Path == "/api/v1/namespaces/default/pods" {
+			if req.URL.Query().Get("includeObject") != string(metav1.IncludeMetadata) {
+				t.Errorf("expected the list to include metadata, got query %q", req.URL.RawQuery)
+			}
+			if err := json.NewEncoder(w).Encode(table); err != nil {
+				t.Errorf("failed to encode response: %v", err)
+			}
+			return
+		}
+		mu.Lock()
+		*lookups = append(*lookups, req.URL.Path)
+		mu.Unlock()
+		owner, ok := owners[req.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","message":"replicasets.apps \"gone\" not found","reason":"NotFound","code":404}`)
+			return
+		}
+		fmt.Fprint(w, owner)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRun_ShowOwner(t *testing.T) {
+	testCases := []struct {
+		name            string
+		top             bool
+		expectedOutput  string
+		expectedLookups []string
+		expectedErrOut  string
+	}{
+		{
+			name: "direct owner",
+			expectedOutput: `Name	Owner
+web-abc-1	ReplicaSet/web-abc
+web-abc-2	ReplicaSet/web-abc
+kube-proxy-node-1	Node/node-1
+standalone	<none>
+orphan-1	ReplicaSet/gone
+`,
+		},
+		{
+			name: "top-level owner",
+			top:  true,
+			expectedOutput: `Name	Owner
+web-abc-1	Deployment/web
+web-abc-2	Deployment/web
+kube-proxy-node-1	Node/node-1
+standalone	<none>
+orphan-1	ReplicaSet/gone
+`,
+			expectedLookups: []string{
+				"/apis/apps/v1/namespaces/default/replicasets/web-abc",
+				"/apis/apps/v1/namespaces/default/deployments/web",
+				"/api/v1/nodes/node-1",
+				"/apis/apps/v1/namespaces/default/replicasets/gone",
+			},
+			expectedErrOut: `Warning: cannot look up owner ReplicaSet/gone: replicasets.apps "gone" not found`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var lookups []string
+			server := newOwnerServer(t, &lookups)
+
+			streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+			opts := &HeadOptions{
+				Resource:   "pods",
+				Namespace:  "default",
+				Limit:      5,
+				ShowOwner:  true,
+				TopOwner:   tc.top,
+				RESTConfig: &rest.Config{Host: server.URL},
+				Mapper:     newDiscoveryRESTMapper(t),
+				IOStreams:  streams,
+				PrintFlags: genericclioptions.NewPrintFlags("").WithDefaultOutput("tsv"),
+			}
+			if err := opts.Validate(); err != nil {
+				t.Fatalf("unexpected error during Validate: %v", err)
+			}
+			if err := opts.Run(); err != nil {
+				t.Fatalf("unexpected error during Run: %v", err)
+			}
+
+			if !strings.HasPrefix(out.String(), tc.expectedOutput) {
+				t.Errorf("expected output to start with:\n%s\ngot:\n%s", tc.expectedOutput, out.String())
+			}
+			// Each owner is looked up once, however many rows it owns.
+			if fmt.Sprint(lookups) != fmt.Sprint(tc.expectedLookups) {
+				t.Errorf("expected owner lookups %v, got %v", tc.expectedLookups, lookups)
+			}
+			if !strings.Contains(errOut.String(), tc.expectedErrOut) {
+				t.Errorf("expected stderr to contain %q, got %q", tc.expectedErrOut, errOut.String())
+			}
+		})
+	}
+}
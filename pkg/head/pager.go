@@ -0,0 +1,148 @@
+package head
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// Pager fetches a resource list from the API server one page at a time, using
+// the server-side limit and continue token. Each page is returned as a Table so
+// that it can be printed with the server's columns.
+//
+// A Pager is not safe for concurrent use.
+type Pager struct {
+	// Client must negotiate for Table output; see NewRestClient.
+	Client rest.Interface
+	// Resource is the resource to list.
+	Resource schema.GroupVersionResource
+	// Namespace to list in. An empty string lists across all namespaces.
+	Namespace string
+	// LabelSelector filters the list by label.
+	LabelSelector string
+	// Limit is the maximum number of items in each page.
+	Limit int64
+	// ResourceVersion and ResourceVersionMatch control which snapshot the first
+	// page is read from. They are not sent with continuation requests, since
+	// the continue token pins the snapshot.
+	ResourceVersion      string
+	ResourceVersionMatch metav1.ResourceVersionMatch
+	// IncludeObject controls whether each row embeds the full object, only
+	// its metadata (the server default), or nothing.
+	IncludeObject metav1.IncludeObjectPolicy
+	// Continue is the token for the next page. It is updated after each page;
+	// an empty token after the first page means the list is exhausted.
+	Continue string
+
+	started   bool
+	lastStats PageStats
+}
+
+// PageStats describes the request for a single page.
+type PageStats struct {
+	// Latency is the time from sending the request to reading the whole response.
+	Latency time.Duration
+	// Bytes is the size of the response body.
+	Bytes int
+	// Rows is the number of rows in the page.
+	Rows int
+	// ResourceVersion is the resourceVersion of the list reported by the server.
+	ResourceVersion string
+	// CacheEligible is true if the request semantics alone allow the API server
+	// to serve it from its watch cache. It is inferred from the request, not
+	// reported by the server, which may serve other requests from the cache too.
+	CacheEligible bool
+}
+
+// NewPager returns a Pager for the resource in the namespace that starts at the
+// beginning of the list with the default limit.
+func NewPager(client rest.Interface, gvr schema.GroupVersionResource, namespace string) *Pager {
+	return &Pager{
+		Client:    client,
+		Resource:  gvr,
+		Namespace: namespace,
+		Limit:     DefaultHeadLimit,
+	}
+}
+
+// Done returns true once the last page of the list has been fetched.
+func (p *Pager) Done() bool {
+	return p.started && p.Continue == ""
+}
+
+// Next fetches the next page of the list and advances the continue token.
+func (p *Pager) Next(ctx context.Context) (*metav1.Table, error) {
+	listOptions := metav1.ListOptions{
+		Limit:         p.Limit,
+		Continue:      p.Continue,
+		LabelSelector: p.LabelSelector,
+	}
+	if p.Continue == "" {
+		listOptions.ResourceVersion = p.ResourceVersion
+		listOptions.ResourceVersionMatch = p.ResourceVersionMatch
+	}
+
+	req := p.Client.Get().
+		Namespace(p.Namespace).
+		Resource(p.Resource.Resource).
+		VersionedParams(&listOptions, scheme.ParameterCodec)
+	if p.IncludeObject != "" {
+		req = req.Param("includeObject", string(p.IncludeObject))
+	}
+
+	start := time.Now()
+	result := req.Do(ctx)
+	body, err := result.Raw()
+	latency := time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &metav1.Table{}
+	if err := result.Into(table); err != nil {
+		return nil, err
+	}
+
+	p.started = true
+	p.Continue = table.Continue
+	p.lastStats = PageStats{
+		Latency:         latency,
+		Bytes:           len(body),
+		Rows:            len(table.Rows),
+		ResourceVersion: table.ResourceVersion,
+		CacheEligible:   cacheEligible(listOptions),
+	}
+	return table, nil
+}
+
+// LastPageStats returns the stats for the most recently fetched page.
+func (p *Pager) LastPageStats() PageStats {
+	return p.lastStats
+}
+
+// cacheEligible returns true if a list with these options may be served from
+// the watch cache of any API server: a first page at a minimum resource
+// version. Servers with ConsistentListFromCache (1.31+) may also serve
+// continuations and consistent lists from the cache.
+func cacheEligible(opts metav1.ListOptions) bool {
+	return opts.Continue == "" && opts.ResourceVersion != "" &&
+		opts.ResourceVersionMatch != metav1.ResourceVersionMatchExact
+}
+
+// Pages returns an iterator over the remaining pages of the list. Iteration
+// stops after the last page or the first error.
+func (p *Pager) Pages(ctx context.Context) iter.Seq2[*metav1.Table, error] {
+	return func(yield func(*metav1.Table, error) bool) {
+		for !p.Done() {
+			table, err := p.Next(ctx)
+			if !yield(table, err) || err != nil {
+				return
+			}
+		}
+	}
+}
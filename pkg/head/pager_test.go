@@ -0,0 +1,154 @@
+package head
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// newPagingRESTClient returns a client for a list of total items served in
+// pages, where the continue token is the index of the next item. Rows embed
+// the pod objects if the request asks for them.
+func newPagingRESTClient(t *testing.T, total int, requests *[]string) rest.Interface {
+	fakeRT := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*requests = append(*requests, req.URL.RawQuery)
+		start := 0
+		if token := req.URL.Query().Get("continue"); token != "" {
+			fmt.Sscanf(token, "%d", &start)
+		}
+		limit := total
+		if l := req.URL.Query().Get("limit"); l != "" {
+			fmt.Sscanf(l, "%d", &limit)
+		}
+
+		table := &metav1.Table{
+			TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+			ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+		}
+		end := min(start+limit, total)
+		for i := start; i < end; i++ {
+			name := fmt.Sprintf("pod-%d", i)
+			row := metav1.TableRow{Cells: []interface{}{name}}
+			if req.URL.Query().Get("includeObject") == string(metav1.IncludeObject) {
+				row.Object.Raw = []byte(fmt.Sprintf(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":%q}}`, name))
+			}
+			table.Rows = append(table.Rows, row)
+		}
+		if end < total {
+			table.Continue = fmt.Sprintf("%d", end)
+			// Like the API server, only count the remaining items without a selector.
+			if req.URL.Query().Get("labelSelector") == "" {
+				remaining := int64(total - end)
+				table.RemainingItemCount = &remaining
+			}
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(mustMarshalJSON(table))),
+		}, nil
+	})
+
+	gv := schema.GroupVersion{Version: "v1"}
+	client, err := rest.RESTClientFor(&rest.Config{
+		Transport: fakeRT,
+		ContentConfig: rest.ContentConfig{
+			GroupVersion:         &gv,
+			NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		},
+		APIPath: "/api",
+	})
+	if err != nil {
+		t.Fatalf("failed to create REST client: %v", err)
+	}
+	return client
+}
+
+func TestPager_Pages(t *testing.T) {
+	var requests []string
+	client := newPagingRESTClient(t, 5, &requests)
+	pager := NewPager(client, schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "default")
+	pager.Limit = 2
+	pager.LabelSelector = "app=web"
+
+	var names []string
+	for table, err := range pager.Pages(context.Background()) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, row := range table.Rows {
+			names = append(names, row.Cells[0].(string))
+		}
+	}
+
+	if fmt.Sprint(names) != "[pod-0 pod-1 pod-2 pod-3 pod-4]" {
+		t.Errorf("expected all pods in order, got %v", names)
+	}
+	if len(requests) != 3 {
+		t.Errorf("expected 3 requests, got %d: %v", len(requests), requests)
+	}
+	if requests[0] != "labelSelector=app%3Dweb&limit=2" {
+		t.Errorf("unexpected query for first page: %q", requests[0])
+	}
+	if !pager.Done() {
+		t.Error("expected pager to be done after the last page")
+	}
+}
+
+func TestPager_StopEarly(t *testing.T) {
+	var requests []string
+	client := newPagingRESTClient(t, 5, &requests)
+	pager := NewPager(client, schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "")
+	pager.Limit = 2
+	pager.Continue = "2"
+
+	for table, err := range pager.Pages(context.Background()) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if table.Rows[0].Cells[0] != "pod-2" {
+			t.Errorf("expected to resume at pod-2, got %v", table.Rows[0].Cells[0])
+		}
+		break
+	}
+
+	if len(requests) != 1 {
+		t.Errorf("expected a single request, got %d", len(requests))
+	}
+	if pager.Done() || pager.Continue != "4" {
+		t.Errorf("expected pager to be resumable at token %q, got done=%v token=%q", "4", pager.Done(), pager.Continue)
+	}
+}
+
+func TestPager_ResourceVersion(t *testing.T) {
+	var requests []string
+	client := newPagingRESTClient(t, 3, &requests)
+	pager := NewPager(client, schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "")
+	pager.Limit = 2
+	pager.ResourceVersion = "100"
+	pager.ResourceVersionMatch = metav1.ResourceVersionMatchNotOlderThan
+
+	for _, err := range pager.Pages(context.Background()) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d: %v", len(requests), requests)
+	}
+	if requests[0] != "limit=2&resourceVersion=100&resourceVersionMatch=NotOlderThan" {
+		t.Errorf("expected first page to request the resource version, got %q", requests[0])
+	}
+	if requests[1] != "continue=2&limit=2" {
+		t.Errorf("expected continuation to omit the resource version, got %q", requests[1])
+	}
+}
@@ -0,0 +1,108 @@
+package head
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/rest"
+)
+
+var namespacesGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// namespacePage is the first page of a resource within a single namespace.
+type namespacePage struct {
+	namespace string
+	table     *metav1.Table
+	err       error
+}
+
+// headPerNamespace returns the first Limit items of the resource from each
+// namespace, merged into a single table with a leading NAMESPACE column.
+// Namespaces are fetched concurrently, with at most Concurrency requests in
+// flight. Namespaces that fail are left out of the table and reported in the
+// returned aggregate error.
+func (o *HeadOptions) headPerNamespace(restClient rest.Interface, gvr schema.GroupVersionResource) (*metav1.Table, error) {
+	if namespaced, err := o.isNamespaced(gvr); err == nil && !namespaced {
+		return nil, fmt.Errorf("--per-namespace cannot be used with %q, which is cluster-scoped", o.Resource)
+	}
+	namespaces, err := o.listNamespaces()
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]namespacePage, len(namespaces))
+	runParallel(len(namespaces), o.Concurrency, func(i int) {
+		table, err := o.newPager(restClient, gvr, namespaces[i]).Next(context.Background())
+		pages[i] = namespacePage{namespace: namespaces[i], table: table, err: err}
+	})
+
+	merged := &metav1.Table{}
+	var errs []error
+	for _, page := range pages {
+		if page.err != nil {
+			errs = append(errs, fmt.Errorf("namespace %q: %w", page.namespace, page.err))
+			continue
+		}
+		if len(page.table.Rows) == 0 {
+			continue
+		}
+		prependColumn(page.table, "Namespace", page.namespace)
+		if len(merged.ColumnDefinitions) == 0 {
+			merged.ColumnDefinitions = page.table.ColumnDefinitions
+		}
+		merged.Rows = append(merged.Rows, page.table.Rows...)
+	}
+	return merged, utilerrors.NewAggregate(errs)
+}
+
+// listNamespaces returns the sorted names of the namespaces matching NamespaceSelector.
+func (o *HeadOptions) listNamespaces() ([]string, error) {
+	list, err := o.DynamicClient.Resource(namespacesGVR).List(context.Background(), metav1.ListOptions{
+		LabelSelector: o.NamespaceSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// prependColumn inserts a string column with the same value in every row at the
+// start of the table.
+func prependColumn(table *metav1.Table, name, value string) {
+	table.ColumnDefinitions = append([]metav1.TableColumnDefinition{{Name: name, Type: "string"}}, table.ColumnDefinitions...)
+	for i := range table.Rows {
+		table.Rows[i].Cells = append([]interface{}{value}, table.Rows[i].Cells...)
+	}
+}
+
+// runParallel calls fn for each index in [0, n), with at most concurrency calls
+// running at once.
+func runParallel(n, concurrency int, fn func(i int)) {
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(concurrency, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+}
@@ -0,0 +1,115 @@
+package head
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+func newNamespace(name string, labels map[string]string) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(name)
+	ns.SetLabels(labels)
+	return ns
+}
+
+func TestRun_PerNamespace(t *testing.T) {
+	// Each namespace gets a single pod named after it, except "empty" which has none.
+	fakeRT := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		parts := strings.Split(req.URL.Path, "/")
+		ns := parts[len(parts)-2]
+		table := &metav1.Table{
+			TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+			ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}, {Name: "Age"}},
+		}
+		if ns != "empty" {
+			table.Rows = []metav1.TableRow{{Cells: []interface{}{"pod-in-" + ns, "1d"}}}
+			table.Continue = "more"
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(mustMarshalJSON(table))),
+		}, nil
+	})
+
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		config.Transport = fakeRT
+		config.GroupVersion = &gv
+		config.APIPath = "/api"
+		config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+		return rest.RESTClientFor(&config)
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{namespacesGVR: "NamespaceList"},
+		newNamespace("team-b", map[string]string{"tier": "prod"}),
+		newNamespace("team-a", map[string]string{"tier": "prod"}),
+		newNamespace("empty", map[string]string{"tier": "prod"}),
+		newNamespace("sandbox", map[string]string{"tier": "dev"}),
+	)
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:          "pods",
+		Limit:             1,
+		PerNamespace:      true,
+		NamespaceSelector: "tier=prod",
+		Concurrency:       2,
+		RESTConfig:        &rest.Config{},
+		Mapper:            fakeRESTMapper(),
+		DynamicClient:     dynamicClient,
+		IOStreams:         streams,
+		PrintFlags:        genericclioptions.NewPrintFlags(""),
+	}
+
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	output := out.String()
+	if !strings.HasPrefix(output, "NAMESPACE") {
+		t.Errorf("expected output to start with a NAMESPACE column, got:\n%s", output)
+	}
+	a, b := strings.Index(output, "pod-in-team-a"), strings.Index(output, "pod-in-team-b")
+	if a == -1 || b == -1 || a > b {
+		t.Errorf("expected one pod from each prod namespace in namespace order, got:\n%s", output)
+	}
+	if strings.Contains(output, "sandbox") {
+		t.Errorf("expected namespaces not matching the selector to be skipped, got:\n%s", output)
+	}
+	if strings.Contains(output, "Continue Token") {
+		t.Errorf("expected no continue token in per-namespace output, got:\n%s", output)
+	}
+}
+
+func TestPrependColumn(t *testing.T) {
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+		Rows:              []metav1.TableRow{{Cells: []interface{}{"a"}}, {Cells: []interface{}{"b"}}},
+	}
+	prependColumn(table, "Cluster", "prod")
+
+	if table.ColumnDefinitions[0].Name != "Cluster" || len(table.ColumnDefinitions) != 2 {
+		t.Errorf("expected Cluster column to be prepended, got %v", table.ColumnDefinitions)
+	}
+	for _, row := range table.Rows {
+		if row.Cells[0] != "prod" || len(row.Cells) != 2 {
+			t.Errorf("expected row to start with the column value, got %v", row.Cells)
+		}
+	}
+}
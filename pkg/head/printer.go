@@ -0,0 +1,223 @@
+package head
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// pagePrinter prints a page of results as soon as it arrives, so that no more
+// than one page needs to be held in memory.
+type pagePrinter interface {
+	PrintPage(table *metav1.Table, w io.Writer) error
+}
+
+// pageFinisher is implemented by page printers that write a trailer after the
+// last page.
+type pageFinisher interface {
+	Finish(w io.Writer) error
+}
+
+// finishPrinting writes the printer's trailer, if it has one.
+func finishPrinting(printer pagePrinter, w io.Writer) error {
+	if finisher, ok := printer.(pageFinisher); ok {
+		return finisher.Finish(w)
+	}
+	return nil
+}
+
+// tablePagePrinter prints pages as human-readable tables using the columns
+// returned by the server.
+type tablePagePrinter struct {
+	printer printers.ResourcePrinter
+}
+
+func (p *tablePagePrinter) PrintPage(table *metav1.Table, w io.Writer) error {
+	return p.printer.PrintObj(table, w)
+}
+
+// objectPagePrinter prints each object embedded in the rows of a page with a
+// printer from the standard output flags (e.g., json, yaml, name).
+type objectPagePrinter struct {
+	printer printers.ResourcePrinter
+}
+
+func (p *objectPagePrinter) PrintPage(table *metav1.Table, w io.Writer) error {
+	for _, row := range table.Rows {
+		obj, err := rowObject(row)
+		if err != nil {
+			return err
+		}
+		if err := p.printer.PrintObj(obj, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ndjsonPagePrinter prints each object embedded in the rows of a page as
+// compact JSON on a single line, so consumers can process objects as they
+// arrive rather than waiting for a complete list document.
+type ndjsonPagePrinter struct{}
+
+func (p *ndjsonPagePrinter) PrintPage(table *metav1.Table, w io.Writer) error {
+	var buf bytes.Buffer
+	for _, row := range table.Rows {
+		buf.Reset()
+		if err := json.Compact(&buf, row.Object.Raw); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// delimitedPagePrinter prints the table rows of each page as CSV (or TSV),
+// with a header row of column names before the first page.
+type delimitedPagePrinter struct {
+	comma         rune
+	wide          bool
+	printedHeader bool
+}
+
+func (p *delimitedPagePrinter) PrintPage(table *metav1.Table, w io.Writer) error {
+	columns := visibleColumns(table, p.wide)
+	writer := csv.NewWriter(w)
+	writer.Comma = p.comma
+
+	if !p.printedHeader && len(table.Rows) > 0 {
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = table.ColumnDefinitions[column].Name
+		}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		p.printedHeader = true
+	}
+	for _, row := range table.Rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = cellString(row, column)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// visibleColumns returns the indexes of the columns to print. Columns with a
+// non-zero priority are only shown in wide output, as in the table printer.
+func visibleColumns(table *metav1.Table, wide bool) []int {
+	var columns []int
+	for i, column := range table.ColumnDefinitions {
+		if wide || column.Priority == 0 {
+			columns = append(columns, i)
+		}
+	}
+	return columns
+}
+
+// cellString returns the printable value of a cell, or "" if the row has no
+// such cell.
+func cellString(row metav1.TableRow, column int) string {
+	if column >= len(row.Cells) || row.Cells[column] == nil {
+		return ""
+	}
+	return fmt.Sprint(row.Cells[column])
+}
+
+// rowObject decodes the object embedded in a table row.
+func rowObject(row metav1.TableRow) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if err := runtime.DecodeInto(unstructured.UnstructuredJSONScheme, row.Object.Raw, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// outputFormat returns the requested output format, or "" for the default table.
+func (o *HeadOptions) outputFormat() string {
+	if o.PrintFlags == nil || o.PrintFlags.OutputFormat == nil {
+		return ""
+	}
+	return *o.PrintFlags.OutputFormat
+}
+
+// tableFormats are the output formats that print the rows of the server's Table
+// rather than whole objects.
+var tableFormats = map[string]bool{
+	"":         true,
+	"wide":     true,
+	"csv":      true,
+	"tsv":      true,
+	"markdown": true,
+	"html":     true,
+}
+
+// documentFormats are the table formats meant for other tools and documents,
+// which can include the wide columns with --wide.
+var documentFormats = map[string]bool{
+	"csv":      true,
+	"tsv":      true,
+	"markdown": true,
+	"html":     true,
+}
+
+// wantsObjects returns true if the output format prints whole objects rather
+// than table rows, in which case the server must embed the objects in the rows.
+func (o *HeadOptions) wantsObjects() bool {
+	return !tableFormats[o.outputFormat()]
+}
+
+// newPagePrinter returns the printer for the requested output format.
+func (o *HeadOptions) newPagePrinter() (pagePrinter, error) {
+	switch o.outputFormat() {
+	case "csv":
+		return &delimitedPagePrinter{comma: ',', wide: o.Wide}, nil
+	case "tsv":
+		return &delimitedPagePrinter{comma: '\t', wide: o.Wide}, nil
+	case "markdown", "html":
+		var link *template.Template
+		if o.LinkTemplate != "" {
+			var err error
+			link, err = template.New("link").Parse(o.LinkTemplate)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing --link-template: %w", err)
+			}
+		}
+		if o.outputFormat() == "html" {
+			return &htmlPagePrinter{wide: o.Wide, link: link}, nil
+		}
+		return &markdownPagePrinter{wide: o.Wide, link: link}, nil
+	}
+
+	if !o.wantsObjects() {
+		return &tablePagePrinter{printer: printers.NewTablePrinter(printers.PrintOptions{
+			Wide: o.outputFormat() == "wide",
+		})}, nil
+	}
+
+	if o.outputFormat() == "ndjson" {
+		return &ndjsonPagePrinter{}, nil
+	}
+
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return nil, err
+	}
+	return &objectPagePrinter{printer: printer}, nil
+}
@@ -0,0 +1,141 @@
+package head
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+func TestRun_NDJSONPage(t *testing.T) {
+	var requests []string
+	client := newPagingRESTClient(t, 5, &requests)
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		return client, nil
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:   "pods",
+		Limit:      2,
+		RESTConfig: &rest.Config{},
+		Mapper:     fakeRESTMapper(),
+		IOStreams:  streams,
+		PrintFlags: genericclioptions.NewPrintFlags("").WithDefaultOutput("ndjson"),
+	}
+
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	// The continue token goes to stderr so that stdout stays a stream of objects.
+	if expected := "{\"apiVersion\":\"v1\",\"kind\":\"Pod\",\"metadata\":{\"name\":\"pod-0\"}}\n"; !strings.HasPrefix(out.String(), expected) {
+		t.Errorf("expected output to start with %q, got %q", expected, out.String())
+	}
+	if strings.Contains(out.String(), "Continue Token") {
+		t.Errorf("expected no continue token on stdout, got:\n%s", out.String())
+	}
+	if !strings.Contains(errOut.String(), "Continue Token: 2") {
+		t.Errorf("expected the continue token on stderr, got %q", errOut.String())
+	}
+}
+
+func TestRun_NDJSON(t *testing.T) {
+	var requests []string
+	client := newPagingRESTClient(t, 5, &requests)
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		return client, nil
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:   "pods",
+		Limit:      2,
+		All:        true,
+		RESTConfig: &rest.Config{},
+		Mapper:     fakeRESTMapper(),
+		IOStreams:  streams,
+		PrintFlags: genericclioptions.NewPrintFlags("").WithDefaultOutput("ndjson"),
+	}
+
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected one line per object, got %d:\n%s", len(lines), out.String())
+	}
+	for i, line := range lines {
+		var obj struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v", i, err)
+		}
+		if want := fmt.Sprintf("pod-%d", i); obj.Metadata.Name != want {
+			t.Errorf("expected line %d to be %s, got %s", i, want, obj.Metadata.Name)
+		}
+	}
+}
+
+func TestDelimitedPagePrinter(t *testing.T) {
+	page := func(names ...string) *metav1.Table {
+		table := &metav1.Table{
+			ColumnDefinitions: []metav1.TableColumnDefinition{
+				{Name: "Name"},
+				{Name: "Status"},
+				{Name: "Node", Priority: 1},
+			},
+		}
+		for _, name := range names {
+			table.Rows = append(table.Rows, metav1.TableRow{Cells: []interface{}{name, `Error: "OOM", exit 137`, nil}})
+		}
+		return table
+	}
+
+	testCases := []struct {
+		name     string
+		printer  *delimitedPagePrinter
+		expected string
+	}{
+		{
+			name:    "csv",
+			printer: &delimitedPagePrinter{comma: ','},
+			expected: "Name,Status\n" +
+				"pod-a,\"Error: \"\"OOM\"\", exit 137\"\n" +
+				"pod-b,\"Error: \"\"OOM\"\", exit 137\"\n",
+		},
+		{
+			name:    "tsv wide",
+			printer: &delimitedPagePrinter{comma: '\t', wide: true},
+			expected: "Name\tStatus\tNode\n" +
+				"pod-a\t\"Error: \"\"OOM\"\", exit 137\"\t\n" +
+				"pod-b\t\"Error: \"\"OOM\"\", exit 137\"\t\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			for _, name := range []string{"pod-a", "pod-b"} {
+				if err := tc.printer.PrintPage(page(name), &out); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tc.expected, out.String())
+			}
+		})
+	}
+}
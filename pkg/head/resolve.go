@@ -0,0 +1,376 @@
+package head
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+// maxSuggestions is the number of close matches suggested for a resource type
+// the server doesn't have.
+const maxSuggestions = 3
+
+// resourceMapping finds the REST mapping for the resource argument, in the
+// version requested with APIVersion if set, and remembers it for isNamespaced.
+func (o *HeadOptions) resourceMapping() (*meta.RESTMapping, error) {
+	mapping := o.qualifiedResourceMapping()
+	if mapping == nil {
+		var err error
+		if mapping, err = o.mapperResourceMapping(); err != nil {
+			return nil, err
+		}
+	}
+	if o.APIVersion != "" {
+		var err error
+		if mapping, err = o.mappingForAPIVersion(mapping); err != nil {
+			return nil, err
+		}
+	}
+	o.mapping = mapping
+	return mapping, nil
+}
+
+// mappingForAPIVersion returns the mapping for the kind of mapping in the
+// version requested with APIVersion, or an error listing the versions the
+// server does serve the kind in.
+func (o *HeadOptions) mappingForAPIVersion(mapping *meta.RESTMapping) (*meta.RESTMapping, error) {
+	gv, err := schema.ParseGroupVersion(o.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Resource.GroupVersion() == gv {
+		return mapping, nil
+	}
+	groupResource := mapping.Resource.GroupResource()
+	if groupResource.Group != gv.Group {
+		return nil, fmt.Errorf("resource type %s is not in the API group of --api-version %s", groupResource, o.APIVersion)
+	}
+
+	gk := mapping.GroupVersionKind.GroupKind()
+	if versioned, err := o.Mapper.RESTMapping(gk, gv.Version); err == nil && versioned.Resource.Version == gv.Version {
+		return versioned, nil
+	}
+	mappings, err := o.Mapper.RESTMappings(gk)
+	if err != nil {
+		return nil, err
+	}
+	served := sets.New[string]()
+	for _, m := range mappings {
+		served.Insert(m.Resource.Version)
+	}
+	versions := served.UnsortedList()
+	sort.Slice(versions, func(i, j int) bool {
+		return version.CompareKubeAwareVersionStrings(versions[i], versions[j]) > 0
+	})
+	return nil, fmt.Errorf("the server doesn't serve %s in version %s; served versions: %s", groupResource, gv.Version, strings.Join(versions, ", "))
+}
+
+// qualifiedResourceMapping resolves a resource argument qualified with its
+// group, such as "deployments.apps" or "ingresses.v1.networking.k8s.io", or
+// any resource name with APIVersion, from the discovery document of that one
+// group version. The Mapper would first discover every group served by the
+// cluster, which on clusters with many custom resources can take far longer
+// than the list itself. It returns nil if the argument isn't a qualified
+// resource name served by the group, in which case the Mapper must resolve it.
+func (o *HeadOptions) qualifiedResourceMapping() *meta.RESTMapping {
+	fullySpecifiedGVR, groupResource := schema.ParseResourceArg(o.Resource)
+	if (groupResource.Group == "" && o.APIVersion == "") || o.RESTConfig == nil {
+		return nil
+	}
+	// The cached discovery client from the ConfigFlags refreshes every group
+	// on a cache miss, so the group version is requested directly.
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(o.RESTConfig)
+	if err != nil {
+		return nil
+	}
+
+	if o.APIVersion != "" {
+		gv, err := schema.ParseGroupVersion(o.APIVersion)
+		if err != nil || (groupResource.Group != "" && groupResource.Group != gv.Group) {
+			return nil
+		}
+		return findResourceMapping(discoveryClient, gv.WithResource(groupResource.Resource))
+	}
+
+	// "ingresses.networking.k8s.io" also parses as version "networking" of
+	// group "k8s.io", so only versions that look like one are tried.
+	if fullySpecifiedGVR != nil && apiVersionPattern.MatchString(fullySpecifiedGVR.Version) {
+		if mapping := findResourceMapping(discoveryClient, *fullySpecifiedGVR); mapping != nil {
+			return mapping
+		}
+	}
+	preferred := preferredVersion(discoveryClient.RESTClient(), groupResource.Group)
+	if preferred == "" {
+		return nil
+	}
+	return findResourceMapping(discoveryClient, groupResource.WithVersion(preferred))
+}
+
+// apiVersionPattern matches the versions of API groups, e.g. v1 or v2beta1.
+var apiVersionPattern = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]+)?$`)
+
+// findResourceMapping returns the REST mapping for the resource, by plural or
+// singular name, from the discovery document of its group version, or nil if
+// the group version doesn't serve it.
+func findResourceMapping(discoveryClient discovery.DiscoveryInterface, gvr schema.GroupVersionResource) *meta.RESTMapping {
+	list, err := discoveryClient.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return nil
+	}
+	for _, resource := range list.APIResources {
+		if resource.Name != gvr.Resource && resource.SingularName != gvr.Resource {
+			continue
+		}
+		scope := meta.RESTScopeRoot
+		if resource.Namespaced {
+			scope = meta.RESTScopeNamespace
+		}
+		return &meta.RESTMapping{
+			Resource:         gvr.GroupVersion().WithResource(resource.Name),
+			GroupVersionKind: gvr.GroupVersion().WithKind(resource.Kind),
+			Scope:            scope,
+		}
+	}
+	return nil
+}
+
+// preferredVersion returns the preferred version of an API group, or "" if
+// the server doesn't serve the group.
+func preferredVersion(client rest.Interface, group string) string {
+	if client == nil {
+		return ""
+	}
+	body, err := client.Get().AbsPath("/apis", group).Do(context.Background()).Raw()
+	if err != nil {
+		return ""
+	}
+	apiGroup := &metav1.APIGroup{}
+	if err := json.Unmarshal(body, apiGroup); err != nil {
+		return ""
+	}
+	return apiGroup.PreferredVersion.Version
+}
+
+// mapperResourceMapping finds the REST mapping for the resource argument with
+// the Mapper, the way "kubectl get" does. The argument may be a resource, a
+// short name or a kind, optionally qualified by a group ("deployments.apps",
+// "Deployment.apps") or by a version and group ("deployments.v1.apps",
+// "Deployment.v1.apps"). Short names are expanded by the shortcut expander in
+// the Mapper.
+func (o *HeadOptions) mapperResourceMapping() (*meta.RESTMapping, error) {
+	// Try the argument as a resource first, as "resource.version.group" and
+	// then as "resource.group".
+	fullySpecifiedGVR, groupResource := schema.ParseResourceArg(o.Resource)
+	// Look for an unqualified name in the group of --api-version, in case
+	// other groups serve the same name.
+	apiGroup := ""
+	if gv, err := schema.ParseGroupVersion(o.APIVersion); err == nil {
+		apiGroup = gv.Group
+	}
+	if groupResource.Group == "" {
+		groupResource.Group = apiGroup
+	}
+	gvk := schema.GroupVersionKind{}
+	if fullySpecifiedGVR != nil {
+		gvk, _ = o.Mapper.KindFor(*fullySpecifiedGVR)
+	}
+	if gvk.Empty() {
+		var err error
+		gvk, err = o.Mapper.KindFor(groupResource.WithVersion(""))
+		if meta.IsAmbiguousError(err) {
+			return nil, o.ambiguousResourceError(err)
+		}
+		if !gvk.Empty() {
+			o.warnIfAmbiguous(groupResource, gvk)
+		}
+	}
+	if !gvk.Empty() {
+		return o.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+
+	// Otherwise try it as a kind, as "Kind.version.group" and then as "Kind.group".
+	fullySpecifiedGVK, groupKind := schema.ParseKindArg(o.Resource)
+	if groupKind.Group == "" {
+		groupKind.Group = apiGroup
+	}
+	if fullySpecifiedGVK != nil {
+		if mapping, err := o.Mapper.RESTMapping(fullySpecifiedGVK.GroupKind(), fullySpecifiedGVK.Version); err == nil {
+			return mapping, nil
+		}
+	}
+	mapping, err := o.Mapper.RESTMapping(groupKind)
+	if err != nil {
+		// Errors other than a failed match come from discovery, and are more
+		// useful to the user as they are.
+		if meta.IsAmbiguousError(err) {
+			return nil, o.ambiguousResourceError(err)
+		}
+		if meta.IsNoMatchError(err) {
+			return nil, o.resourceNotFoundError()
+		}
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// ambiguousResourceError lists the resource types that an ambiguous resource
+// argument matched, so the user can pick one.
+func (o *HeadOptions) ambiguousResourceError(err error) error {
+	var gvrs []schema.GroupVersionResource
+	var gvks []schema.GroupVersionKind
+	var resourceErr *meta.AmbiguousResourceError
+	var kindErr *meta.AmbiguousKindError
+	switch {
+	case errors.As(err, &resourceErr):
+		gvrs, gvks = resourceErr.MatchingResources, resourceErr.MatchingKinds
+	case errors.As(err, &kindErr):
+		gvrs, gvks = kindErr.MatchingResources, kindErr.MatchingKinds
+	}
+
+	candidates := sets.New[string]()
+	for _, gvr := range gvrs {
+		candidates.Insert(gvr.GroupResource().String())
+	}
+	if candidates.Len() == 0 {
+		for _, gvk := range gvks {
+			candidates.Insert(gvk.GroupKind().String())
+		}
+	}
+	if candidates.Len() == 0 {
+		return err
+	}
+	return fmt.Errorf("resource type %q is ambiguous; specify one of: %s", o.Resource, strings.Join(sets.List(candidates), ", "))
+}
+
+// warnIfAmbiguous warns if a resource argument without a group matched
+// resources of the same kind in more than one group, and the Mapper picked the
+// kind in gvk by the priority of its group. Resources of other kinds that share
+// the name, such as pods.metrics.k8s.io, are not confused with it.
+func (o *HeadOptions) warnIfAmbiguous(groupResource schema.GroupResource, gvk schema.GroupVersionKind) {
+	if groupResource.Group != "" || o.ErrOut == nil {
+		return
+	}
+	gvrs, err := o.Mapper.ResourcesFor(groupResource.WithVersion(""))
+	if err != nil {
+		return
+	}
+	candidates := sets.New[string]()
+	picked := ""
+	for _, gvr := range gvrs {
+		if kind, err := o.Mapper.KindFor(gvr); err != nil || kind.Kind != gvk.Kind {
+			continue
+		}
+		candidates.Insert(gvr.GroupResource().String())
+		if gvr.Group == gvk.Group {
+			picked = gvr.GroupResource().String()
+		}
+	}
+	if candidates.Len() < 2 || picked == "" {
+		return
+	}
+	fmt.Fprintf(o.ErrOut, "Warning: resource type %q matches %s; using %s. Qualify the type with a group to use another.\n",
+		o.Resource, strings.Join(sets.List(candidates), ", "), picked)
+}
+
+// resourceNotFoundError returns the error for a resource argument that matched
+// nothing, suggesting the closest resource types served by the cluster.
+func (o *HeadOptions) resourceNotFoundError() error {
+	err := fmt.Errorf("the server doesn't have a resource type %q", o.Resource)
+	suggestions := o.suggestResourceTypes()
+	switch len(suggestions) {
+	case 0:
+		return err
+	case 1:
+		return fmt.Errorf("%w; did you mean %s?", err, suggestions[0])
+	default:
+		last := len(suggestions) - 1
+		return fmt.Errorf("%w; did you mean %s or %s?", err, strings.Join(suggestions[:last], ", "), suggestions[last])
+	}
+}
+
+// suggestResourceTypes returns the resource types served by the cluster whose
+// names, singular names, short names or kinds are within a small edit distance
+// of the resource argument, closest first.
+func (o *HeadOptions) suggestResourceTypes() []string {
+	if o.ConfigFlags == nil {
+		return nil
+	}
+	resources, err := o.listableResources()
+	if err != nil {
+		return nil
+	}
+
+	arg := strings.ToLower(o.Resource)
+	// Allow roughly one typo for every three characters.
+	maxDistance := max(1, len(arg)/3)
+	distances := map[string]int{}
+	for _, resource := range resources {
+		name := resourceTypeName(resource)
+		for _, term := range append([]string{resource.Name, resource.SingularName, strings.ToLower(resource.Kind)}, resource.ShortNames...) {
+			if term == "" {
+				continue
+			}
+			distance := editDistance(arg, term)
+			if resource.Group != "" {
+				distance = min(distance, editDistance(arg, term+"."+resource.Group))
+			}
+			if current, ok := distances[name]; distance <= maxDistance && (!ok || distance < current) {
+				distances[name] = distance
+			}
+		}
+	}
+
+	suggestions := make([]string, 0, len(distances))
+	for name := range distances {
+		suggestions = append(suggestions, name)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if distances[suggestions[i]] != distances[suggestions[j]] {
+			return distances[suggestions[i]] < distances[suggestions[j]]
+		}
+		return suggestions[i] < suggestions[j]
+	})
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and
+// transpositions of adjacent characters needed to turn a into b.
+func editDistance(a, b string) int {
+	// d[i][j] is the distance between the first i bytes of a and the first j bytes of b.
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
@@ -0,0 +1,291 @@
+package head
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestGetResourceGVR_Ambiguous(t *testing.T) {
+	testCases := []struct {
+		name            string
+		resourceArg     string
+		mapper          meta.RESTMapper
+		expectedGVR     schema.GroupVersionResource
+		expectedWarning string
+		expectedError   string
+	}{
+		{
+			name:            "picked by group priority",
+			resourceArg:     "events",
+			mapper:          newDiscoveryRESTMapper(t),
+			expectedGVR:     schema.GroupVersionResource{Version: "v1", Resource: "events"},
+			expectedWarning: `Warning: resource type "events" matches events, events.events.k8s.io; using events.`,
+		},
+		{
+			name:        "same name in another kind",
+			resourceArg: "pods",
+			mapper:      newDiscoveryRESTMapper(t),
+			expectedGVR: schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+		},
+		{
+			name:        "metrics qualified with a group",
+			resourceArg: "pods.metrics.k8s.io",
+			mapper:      newDiscoveryRESTMapper(t),
+			expectedGVR: schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"},
+		},
+		{
+			name:        "qualified with a group",
+			resourceArg: "events.events.k8s.io",
+			mapper:      newDiscoveryRESTMapper(t),
+			expectedGVR: schema.GroupVersionResource{Group: "events.k8s.io", Version: "v1", Resource: "events"},
+		},
+		{
+			name:        "ambiguous",
+			resourceArg: "widgets",
+			mapper: &fakeRESTMapperImpl{
+				err: &meta.AmbiguousResourceError{
+					PartialResource: schema.GroupVersionResource{Resource: "widgets"},
+					MatchingResources: []schema.GroupVersionResource{
+						{Group: "b.example.com", Version: "v1", Resource: "widgets"},
+						{Group: "a.example.com", Version: "v1", Resource: "widgets"},
+						{Group: "a.example.com", Version: "v1beta1", Resource: "widgets"},
+					},
+				},
+			},
+			expectedError: `resource type "widgets" is ambiguous; specify one of: widgets.a.example.com, widgets.b.example.com`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			streams, _, _, errOut := genericclioptions.NewTestIOStreams()
+			opts := &HeadOptions{Resource: tc.resourceArg, Mapper: tc.mapper, IOStreams: streams}
+
+			gvr, err := opts.GetResourceGVR()
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Errorf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gvr != tc.expectedGVR {
+				t.Errorf("expected gvr %v, got %v", tc.expectedGVR, gvr)
+			}
+			if tc.expectedWarning == "" && errOut.Len() > 0 {
+				t.Errorf("expected no warning, got %q", errOut.String())
+			}
+			if !strings.HasPrefix(errOut.String(), tc.expectedWarning) {
+				t.Errorf("expected warning %q, got %q", tc.expectedWarning, errOut.String())
+			}
+		})
+	}
+}
+
+func TestGetResourceGVR_Suggestions(t *testing.T) {
+	server := newFakeAPIServer(t, "pod-a")
+	kubeconfig := writeKubeconfig(t, map[string]string{"test": server.URL})
+	cacheDir := t.TempDir()
+	contextName := "test"
+
+	testCases := []struct {
+		resourceArg   string
+		expectedError string
+	}{
+		{
+			resourceArg:   "pdos",
+			expectedError: `the server doesn't have a resource type "pdos"; did you mean pods?`,
+		},
+		{
+			resourceArg:   "Pdo",
+			expectedError: `the server doesn't have a resource type "Pdo"; did you mean pods?`,
+		},
+		{
+			resourceArg:   "secrets",
+			expectedError: `the server doesn't have a resource type "secrets"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.resourceArg, func(t *testing.T) {
+			opts := NewHeadOptions(genericclioptions.NewTestIOStreamsDiscard())
+			opts.ConfigFlags.KubeConfig = &kubeconfig
+			opts.ConfigFlags.CacheDir = &cacheDir
+			opts.ConfigFlags.Context = &contextName
+			if err := opts.Complete(tc.resourceArg); err != nil {
+				t.Fatalf("unexpected error during Complete: %v", err)
+			}
+
+			_, err := opts.GetResourceGVR()
+			if err == nil || err.Error() != tc.expectedError {
+				t.Errorf("expected error %q, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestGetResourceGVR_APIVersion(t *testing.T) {
+	widgets := schema.GroupVersionResource{Group: "example.com", Resource: "widgets"}
+	testCases := []struct {
+		resourceArg   string
+		apiVersion    string
+		expectedGVR   schema.GroupVersionResource
+		expectedError string
+	}{
+		{
+			resourceArg: "widgets",
+			expectedGVR: widgets.GroupResource().WithVersion("v1"),
+		},
+		{
+			resourceArg: "widgets",
+			apiVersion:  "example.com/v1beta1",
+			expectedGVR: widgets.GroupResource().WithVersion("v1beta1"),
+		},
+		{
+			resourceArg: "widgets.example.com",
+			apiVersion:  "example.com/v1beta1",
+			expectedGVR: widgets.GroupResource().WithVersion("v1beta1"),
+		},
+		{
+			resourceArg: "Widget",
+			apiVersion:  "example.com/v1beta1",
+			expectedGVR: widgets.GroupResource().WithVersion("v1beta1"),
+		},
+		{
+			// The core group is preferred for "events" without --api-version.
+			resourceArg: "events",
+			apiVersion:  "events.k8s.io/v1",
+			expectedGVR: schema.GroupVersionResource{Group: "events.k8s.io", Version: "v1", Resource: "events"},
+		},
+		{
+			resourceArg:   "widgets",
+			apiVersion:    "example.com/v2",
+			expectedError: "the server doesn't serve widgets.example.com in version v2; served versions: v1, v1beta1",
+		},
+		{
+			resourceArg:   "deployments.apps",
+			apiVersion:    "example.com/v1",
+			expectedError: "resource type deployments.apps is not in the API group of --api-version example.com/v1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.resourceArg+" "+tc.apiVersion, func(t *testing.T) {
+			opts := &HeadOptions{
+				Resource:   tc.resourceArg,
+				APIVersion: tc.apiVersion,
+				Mapper:     newDiscoveryRESTMapper(t),
+				IOStreams:  genericclioptions.NewTestIOStreamsDiscard(),
+			}
+			gvr, err := opts.GetResourceGVR()
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Errorf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gvr != tc.expectedGVR {
+				t.Errorf("expected gvr %v, got %v", tc.expectedGVR, gvr)
+			}
+		})
+	}
+}
+
+func TestGetResourceGVR_Qualified(t *testing.T) {
+	apps := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	testCases := []struct {
+		resourceArg   string
+		apiVersion    string
+		expectedGVR   schema.GroupVersionResource
+		expectedPaths []string
+	}{
+		{
+			resourceArg:   "deployments.v1.apps",
+			expectedGVR:   apps,
+			expectedPaths: []string{"/apis/apps/v1"},
+		},
+		{
+			resourceArg:   "deployments.apps",
+			expectedGVR:   apps,
+			expectedPaths: []string{"/apis/apps", "/apis/apps/v1"},
+		},
+		{
+			resourceArg:   "deployment.apps",
+			expectedGVR:   apps,
+			expectedPaths: []string{"/apis/apps", "/apis/apps/v1"},
+		},
+		{
+			resourceArg:   "pods",
+			apiVersion:    "v1",
+			expectedGVR:   schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+			expectedPaths: []string{"/api/v1"},
+		},
+		{
+			// Short names need the Mapper, which discovers every group.
+			resourceArg: "deploy",
+			expectedGVR: apps,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.resourceArg+" "+tc.apiVersion, func(t *testing.T) {
+			var paths []string
+			server := newDiscoveryServer(t, &paths)
+			kubeconfig := writeKubeconfig(t, map[string]string{"test": server.URL})
+			contextName := "test"
+			cacheDir := t.TempDir()
+
+			opts := NewHeadOptions(genericclioptions.NewTestIOStreamsDiscard())
+			opts.ConfigFlags.KubeConfig = &kubeconfig
+			opts.ConfigFlags.Context = &contextName
+			opts.ConfigFlags.CacheDir = &cacheDir
+			opts.APIVersion = tc.apiVersion
+			if err := opts.Complete(tc.resourceArg); err != nil {
+				t.Fatalf("unexpected error during Complete: %v", err)
+			}
+
+			gvr, err := opts.GetResourceGVR()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gvr != tc.expectedGVR {
+				t.Errorf("expected gvr %v, got %v", tc.expectedGVR, gvr)
+			}
+			if namespaced, err := opts.isNamespaced(gvr); err != nil || !namespaced {
+				t.Errorf("expected %s to be namespaced, got %v, %v", gvr, namespaced, err)
+			}
+			if tc.expectedPaths != nil && !slices.Equal(paths, tc.expectedPaths) {
+				t.Errorf("expected requests for %v, got %v", tc.expectedPaths, paths)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"pods", "pods", 0},
+		{"pdos", "pods", 1},
+		{"deploymnets", "deployments", 1},
+		{"pod", "pods", 1},
+		{"svc", "services", 5},
+		{"", "pods", 4},
+	}
+	for _, tc := range testCases {
+		if got := editDistance(tc.a, tc.b); got != tc.expected {
+			t.Errorf("editDistance(%q, %q) = %d, expected %d", tc.a, tc.b, got, tc.expected)
+		}
+	}
+}
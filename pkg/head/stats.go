@@ -0,0 +1,111 @@
+package head
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// statsRecorder reports the stats of each page on ErrOut as it is fetched,
+// followed by a summary if more than one page was fetched. A nil recorder
+// records nothing.
+type statsRecorder struct {
+	format string
+	w      io.Writer
+
+	pages   int
+	rows    int
+	bytes   int
+	latency time.Duration
+}
+
+// statsRecord is the JSON form of the stats for a page or the summary.
+type statsRecord struct {
+	Type            string  `json:"type"`
+	Page            int     `json:"page,omitempty"`
+	Pages           int     `json:"pages,omitempty"`
+	LatencySeconds  float64 `json:"latencySeconds"`
+	Bytes           int     `json:"bytes"`
+	Rows            int     `json:"rows"`
+	ResourceVersion string  `json:"resourceVersion,omitempty"`
+	CacheEligible   *bool   `json:"cacheEligible,omitempty"`
+}
+
+// newStatsRecorder returns a recorder for the --stats format, or nil if stats
+// were not requested.
+func (o *HeadOptions) newStatsRecorder() *statsRecorder {
+	if o.Stats == "" {
+		return nil
+	}
+	return &statsRecorder{format: o.Stats, w: o.ErrOut}
+}
+
+// Record reports the stats for a single page.
+func (r *statsRecorder) Record(stats PageStats) {
+	if r == nil {
+		return
+	}
+	r.pages++
+	r.rows += stats.Rows
+	r.bytes += stats.Bytes
+	r.latency += stats.Latency
+
+	if r.format == "json" {
+		r.writeJSON(statsRecord{
+			Type:            "page",
+			Page:            r.pages,
+			LatencySeconds:  stats.Latency.Seconds(),
+			Bytes:           stats.Bytes,
+			Rows:            stats.Rows,
+			ResourceVersion: stats.ResourceVersion,
+			CacheEligible:   &stats.CacheEligible,
+		})
+		return
+	}
+
+	fmt.Fprintf(r.w, "page %d: %s, %s, %d rows, resourceVersion %s, cacheable: %t\n",
+		r.pages, stats.Latency.Round(time.Millisecond), formatBytes(stats.Bytes), stats.Rows, stats.ResourceVersion, stats.CacheEligible)
+}
+
+// Finish reports the totals across all pages if more than one was fetched.
+func (r *statsRecorder) Finish() {
+	if r == nil || r.pages < 2 {
+		return
+	}
+
+	if r.format == "json" {
+		r.writeJSON(statsRecord{
+			Type:           "summary",
+			Pages:          r.pages,
+			LatencySeconds: r.latency.Seconds(),
+			Bytes:          r.bytes,
+			Rows:           r.rows,
+		})
+		return
+	}
+	fmt.Fprintf(r.w, "total: %d pages, %d rows, %s in %s\n",
+		r.pages, r.rows, formatBytes(r.bytes), r.latency.Round(time.Millisecond))
+}
+
+func (r *statsRecorder) writeJSON(record statsRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(r.w, string(data))
+}
+
+// formatBytes formats a byte count with a binary unit suffix.
+func formatBytes(n int) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := unit, 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,72 @@
+package head
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+func TestRun_StatsJSON(t *testing.T) {
+	var requests []string
+	client := newPagingRESTClient(t, 5, &requests)
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		return client, nil
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	streams, _, _, errOut := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:   "pods",
+		Limit:      2,
+		All:        true,
+		Stats:      "json",
+		RESTConfig: &rest.Config{},
+		Mapper:     fakeRESTMapper(),
+		IOStreams:  streams,
+		PrintFlags: genericclioptions.NewPrintFlags(""),
+	}
+
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(errOut.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 3 page records and a summary, got:\n%s", errOut.String())
+	}
+	var records []statsRecord
+	for _, line := range lines {
+		var record statsRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid JSON stats line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if records[0].Type != "page" || records[0].Rows != 2 || records[0].Bytes == 0 {
+		t.Errorf("unexpected first page record: %+v", records[0])
+	}
+	if records[0].CacheEligible == nil || *records[0].CacheEligible {
+		t.Errorf("expected first page to be reported as not cache eligible, got %+v", records[0])
+	}
+	if records[3].Type != "summary" || records[3].Pages != 3 || records[3].Rows != 5 {
+		t.Errorf("unexpected summary record: %+v", records[3])
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	testCases := map[int]string{
+		0:           "0B",
+		1023:        "1023B",
+		1536:        "1.5KiB",
+		5 * 1 << 20: "5.0MiB",
+	}
+	for n, expected := range testCases {
+		if got := formatBytes(n); got != expected {
+			t.Errorf("formatBytes(%d): expected %q, got %q", n, expected, got)
+		}
+	}
+}
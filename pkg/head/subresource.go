@@ -0,0 +1,180 @@
+package head
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/rest"
+)
+
+// supportedSubresources are the subresources that can be fetched with
+// --subresource, as in "kubectl get".
+var supportedSubresources = []string{"status", "scale"}
+
+// subresourceColumns are the columns of the table built on the client for
+// subresources that the server can't return as a Table.
+var subresourceColumns = []metav1.TableColumnDefinition{
+	{Name: "Name", Type: "string", Format: "name"},
+	{Name: "Spec", Type: "string"},
+	{Name: "Status", Type: "string"},
+}
+
+// subresourceTable fetches the subresource of each object in a page of the
+// list, with at most Concurrency requests in flight, and returns them as a
+// single table in the order of the page. Objects deleted since the page was
+// listed are left out. If the server printed some subresources as a Table but
+// not others, all of them are shown in the table built on the client, so every
+// row is under the same columns.
+func (o *HeadOptions) subresourceTable(restClient rest.Interface, gvr schema.GroupVersionResource, page *metav1.Table) (*metav1.Table, error) {
+	tables := make([]*metav1.Table, len(page.Rows))
+	errs := make([]error, len(page.Rows))
+	runParallel(len(page.Rows), o.Concurrency, func(i int) {
+		obj, err := rowObject(page.Rows[i])
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		tables[i], err = o.getSubresource(restClient, gvr, obj.GetNamespace(), obj.GetName())
+		switch {
+		case err == nil:
+		case isObjectNotFound(err, obj.GetName()):
+			// The object was deleted since the page was listed.
+		case apierrors.IsNotFound(err):
+			errs[i] = fmt.Errorf("the server doesn't have a %s subresource for %s: %w", o.Subresource, gvr.GroupResource(), err)
+		default:
+			errs[i] = fmt.Errorf("%s %q: %w", o.Subresource, obj.GetName(), err)
+		}
+	})
+	if err := utilerrors.NewAggregate(errs); err != nil {
+		return nil, err
+	}
+
+	var columns []metav1.TableColumnDefinition
+	for _, table := range tables {
+		if table == nil {
+			continue
+		}
+		if columns == nil {
+			columns = table.ColumnDefinitions
+		}
+		if !sameColumns(columns, table.ColumnDefinitions) {
+			columns = subresourceColumns
+			break
+		}
+	}
+
+	merged := &metav1.Table{ListMeta: page.ListMeta, ColumnDefinitions: columns}
+	for _, table := range tables {
+		if table == nil {
+			continue
+		}
+		if !sameColumns(columns, table.ColumnDefinitions) {
+			var err error
+			if table, err = clientSubresourceTable(table); err != nil {
+				return nil, err
+			}
+		}
+		merged.Rows = append(merged.Rows, table.Rows...)
+	}
+	return merged, nil
+}
+
+// isObjectNotFound returns true if err reports that the named object doesn't
+// exist, rather than its subresource or the request path.
+func isObjectNotFound(err error, name string) bool {
+	var status apierrors.APIStatus
+	if !apierrors.IsNotFound(err) || !errors.As(err, &status) {
+		return false
+	}
+	details := status.Status().Details
+	return details != nil && details.Name == name
+}
+
+// sameColumns returns true if both tables have columns of the same names.
+func sameColumns(a, b []metav1.TableColumnDefinition) bool {
+	return slices.EqualFunc(a, b, func(x, y metav1.TableColumnDefinition) bool {
+		return x.Name == y.Name
+	})
+}
+
+// getSubresource fetches the subresource of a single object. The request
+// negotiates for a Table like the list, including the subresource object in
+// each row; if the server returns the object instead, it is shown in a compact
+// table built on the client.
+func (o *HeadOptions) getSubresource(restClient rest.Interface, gvr schema.GroupVersionResource, namespace, name string) (*metav1.Table, error) {
+	result := restClient.Get().
+		NamespaceIfScoped(namespace, namespace != "").
+		Resource(gvr.Resource).
+		Name(name).
+		SubResource(o.Subresource).
+		Param("includeObject", string(metav1.IncludeObject)).
+		Do(context.Background())
+	body, err := result.Raw()
+	if err != nil {
+		// Error returns the Status sent by the server, whose details name the
+		// object only if the object itself wasn't found.
+		return nil, result.Error()
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(body, &obj.Object); err != nil {
+		return nil, err
+	}
+	if obj.GetKind() == "Table" {
+		table := &metav1.Table{}
+		if err := json.Unmarshal(body, table); err != nil {
+			return nil, err
+		}
+		return table, nil
+	}
+	return &metav1.Table{
+		ColumnDefinitions: subresourceColumns,
+		Rows:              []metav1.TableRow{subresourceRow(obj, body)},
+	}, nil
+}
+
+// clientSubresourceTable rebuilds a Table printed by the server as the table
+// built on the client, from the subresource object in each row.
+func clientSubresourceTable(table *metav1.Table) (*metav1.Table, error) {
+	rebuilt := &metav1.Table{ColumnDefinitions: subresourceColumns}
+	for _, row := range table.Rows {
+		obj, err := rowObject(row)
+		if err != nil {
+			return nil, err
+		}
+		rebuilt.Rows = append(rebuilt.Rows, subresourceRow(obj, row.Object.Raw))
+	}
+	return rebuilt, nil
+}
+
+// subresourceRow returns the row of the table built on the client for a
+// subresource object, whose encoding is body.
+func subresourceRow(obj *unstructured.Unstructured, body []byte) metav1.TableRow {
+	return metav1.TableRow{
+		Cells:  []interface{}{obj.GetName(), compactField(obj, "spec"), compactField(obj, "status")},
+		Object: runtime.RawExtension{Raw: body},
+	}
+}
+
+// compactField returns a top-level field of the object as compact JSON, or ""
+// if the object doesn't have it.
+func compactField(obj *unstructured.Unstructured, field string) string {
+	value, ok := obj.Object[field]
+	if !ok {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
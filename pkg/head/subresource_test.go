@@ -0,0 +1,161 @@
+package head
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+func TestRun_Subresource(t *testing.T) {
+	list := &metav1.Table{
+		TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+	}
+	for _, name := range []string{"web", "api", "gone"} {
+		list.Rows = append(list.Rows, metav1.TableRow{
+			Cells: []interface{}{name},
+			Object: runtime.RawExtension{Raw: []byte(fmt.Sprintf(
+				`{"apiVersion":"meta.k8s.io/v1","kind":"PartialObjectMetadata","metadata":{"name":%q,"namespace":"default"}}`, name))},
+		})
+	}
+	scaleTable := &metav1.Table{
+		TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}, {Name: "Desired"}, {Name: "Available"}},
+		Rows: []metav1.TableRow{{
+			Cells:  []interface{}{"web", int64(3), int64(3)},
+			Object: runtime.RawExtension{Raw: []byte(`{"apiVersion":"autoscaling/v1","kind":"Scale","metadata":{"name":"web"},"spec":{"replicas":3},"status":{"replicas":3}}`)},
+		}},
+	}
+
+	var listQuery string
+	fakeRT := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		respond := func(status int, body []byte) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: status,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			}, nil
+		}
+		switch req.URL.Path {
+		case "/apis/apps/v1/namespaces/default/deployments":
+			listQuery = req.URL.RawQuery
+			return respond(http.StatusOK, mustMarshalJSON(list))
+		case "/apis/apps/v1/namespaces/default/deployments/web/scale":
+			return respond(http.StatusOK, mustMarshalJSON(scaleTable))
+		case "/apis/apps/v1/namespaces/default/deployments/api/scale":
+			// A server that can't print the subresource returns the object.
+			return respond(http.StatusOK, []byte(`{"apiVersion":"autoscaling/v1","kind":"Scale","metadata":{"name":"api"},"spec":{"replicas":2},"status":{"replicas":1}}`))
+		default:
+			// The object was deleted since the page was listed.
+			return respond(http.StatusNotFound, []byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","details":{"name":"gone","group":"apps","kind":"deployments"},"code":404}`))
+		}
+	})
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		config.Transport = fakeRT
+		config.GroupVersion = &gv
+		config.APIPath = "/apis"
+		config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+		return rest.RESTClientFor(&config)
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:    "deployments",
+		Namespace:   "default",
+		Limit:       3,
+		Subresource: "scale",
+		Concurrency: 2,
+		RESTConfig:  &rest.Config{},
+		Mapper: &fakeRESTMapperImpl{
+			gvr:  schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			kind: "Deployment",
+		},
+		IOStreams:  streams,
+		PrintFlags: genericclioptions.NewPrintFlags("").WithDefaultOutput("csv"),
+	}
+
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("unexpected error during Validate: %v", err)
+	}
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	if listQuery != "includeObject=Metadata&limit=3" {
+		t.Errorf("expected the list to request only metadata, got query %q", listQuery)
+	}
+	// Since the server printed only some of the subresources, all of them are
+	// shown in the table built on the client.
+	expected := "Name,Spec,Status\n" +
+		"web,\"{\"\"replicas\"\":3}\",\"{\"\"replicas\"\":3}\"\n" +
+		"api,\"{\"\"replicas\"\":2}\",\"{\"\"replicas\"\":1}\"\n"
+	if !strings.HasPrefix(out.String(), expected) {
+		t.Errorf("expected output to start with:\n%s\ngot:\n%s", expected, out.String())
+	}
+	if strings.Contains(out.String(), "gone") {
+		t.Errorf("expected deleted objects to be left out, got:\n%s", out.String())
+	}
+}
+
+func TestRun_SubresourceNotServed(t *testing.T) {
+	list := &metav1.Table{
+		TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+		Rows: []metav1.TableRow{{
+			Cells:  []interface{}{"pod-a"},
+			Object: runtime.RawExtension{Raw: []byte(`{"apiVersion":"meta.k8s.io/v1","kind":"PartialObjectMetadata","metadata":{"name":"pod-a","namespace":"default"}}`)},
+		}},
+	}
+	fakeRT := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		status, body := http.StatusOK, mustMarshalJSON(list)
+		if req.URL.Path != "/api/v1/namespaces/default/pods" {
+			// Pods have no scale subresource, so the path isn't found.
+			status, body = http.StatusNotFound, []byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"the server could not find the requested resource","reason":"NotFound","details":{},"code":404}`)
+		}
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}, nil
+	})
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		config.Transport = fakeRT
+		config.GroupVersion = &gv
+		config.APIPath = "/api"
+		config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+		return rest.RESTClientFor(&config)
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:    "pods",
+		Namespace:   "default",
+		Limit:       1,
+		Subresource: "scale",
+		Concurrency: 1,
+		RESTConfig:  &rest.Config{},
+		Mapper:      fakeRESTMapper(),
+		IOStreams:   streams,
+		PrintFlags:  genericclioptions.NewPrintFlags(""),
+	}
+	err := opts.Run()
+	expected := "the server doesn't have a scale subresource for pods: the server could not find the requested resource"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %q, got %v", expected, err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("expected no output, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,201 @@
+package head
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/rest"
+)
+
+// continueTokenAPIVersion is the only continue token version the API server issues.
+const continueTokenAPIVersion = "meta.k8s.io/v1"
+
+// ContinueToken is the decoded form of a continue token issued by the API
+// server. Tokens are an implementation detail of the server's etcd storage,
+// so decoding is best effort.
+type ContinueToken struct {
+	// APIVersion is the version of the token encoding.
+	APIVersion string `json:"v"`
+	// ResourceVersion is the snapshot of the list the token continues.
+	ResourceVersion int64 `json:"rv"`
+	// StartKey is the storage key, relative to the resource's key prefix, that
+	// the next page starts at.
+	StartKey string `json:"start"`
+}
+
+// DecodeContinueToken decodes a continue token returned by the API server.
+func DecodeContinueToken(token string) (*ContinueToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("continue token is not valid base64: %w", err)
+	}
+	decoded := &ContinueToken{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		return nil, fmt.Errorf("continue token is not valid JSON: %w", err)
+	}
+	if decoded.APIVersion != continueTokenAPIVersion {
+		return nil, fmt.Errorf("continue token has unrecognized version %q", decoded.APIVersion)
+	}
+	if decoded.ResourceVersion <= 0 {
+		return nil, fmt.Errorf("continue token has invalid resourceVersion %d", decoded.ResourceVersion)
+	}
+	if decoded.StartKey == "" {
+		return nil, fmt.Errorf("continue token has an empty start key")
+	}
+	return decoded, nil
+}
+
+// staleContinueRevisions is how many revisions the snapshot of a --continue
+// token can fall behind the current resourceVersion before head warns about it.
+const staleContinueRevisions = 1000
+
+// warnIfStaleContinue warns on ErrOut if the snapshot pinned by the continue
+// token is far behind the current resourceVersion of the list. This costs one
+// extra single-item request. It isn't made with resourceVersion=0, since API
+// servers may ignore the limit of reads from their watch cache and return the
+// whole list. Failures are ignored, since the list request that follows
+// reports any real problem with the token or the server.
+func (o *HeadOptions) warnIfStaleContinue(restClient rest.Interface, gvr schema.GroupVersionResource, ns string) {
+	token, err := DecodeContinueToken(o.ContinueToken)
+	if err != nil {
+		return
+	}
+
+	pager := o.newPager(restClient, gvr, ns)
+	pager.Limit = 1
+	pager.IncludeObject = metav1.IncludeNone
+	table, err := pager.Next(context.Background())
+	if err != nil {
+		return
+	}
+	current, err := strconv.ParseInt(table.ResourceVersion, 10, 64)
+	if err != nil {
+		return
+	}
+
+	if behind := current - token.ResourceVersion; behind > staleContinueRevisions {
+		fmt.Fprintf(o.ErrOut, "Warning: the continue token reads from resourceVersion %d, which is %d revisions behind the current resourceVersion %d. "+
+			"Changes since then are not shown; run again without --continue for current results.\n",
+			token.ResourceVersion, behind, current)
+	}
+}
+
+// InspectToken prints the contents of a continue token. If a resource type was
+// given to Complete, the token is also checked against that resource on the
+// server, and its position in the list is estimated.
+func (o *HeadOptions) InspectToken(token string) error {
+	decoded, err := DecodeContinueToken(token)
+	if err != nil {
+		return err
+	}
+
+	w := printers.GetNewTabWriter(o.Out)
+	defer w.Flush()
+
+	// The start key is the key of the last object on the previous page with a
+	// NUL byte appended, relative to the key prefix of the list.
+	after := strings.TrimSuffix(decoded.StartKey, "\x00")
+	fmt.Fprintf(w, "Version:\t%s\n", decoded.APIVersion)
+	fmt.Fprintf(w, "Resource Version:\t%d\n", decoded.ResourceVersion)
+	fmt.Fprintf(w, "Start Key:\t%q\n", decoded.StartKey)
+	fmt.Fprintf(w, "Resumes After:\t%s\n", after)
+
+	if o.Resource == "" {
+		return nil
+	}
+	return o.inspectTokenOnServer(w, token, after)
+}
+
+// inspectTokenOnServer checks that the token can continue a list of the
+// resource, and estimates the position of the next page from the server's
+// remaining item counts.
+func (o *HeadOptions) inspectTokenOnServer(w io.Writer, token, after string) error {
+	gvr, err := o.GetResourceGVR()
+	if err != nil {
+		return err
+	}
+	namespaced, err := o.isNamespaced(gvr)
+	if err != nil {
+		return err
+	}
+
+	// Lists across all namespaces have keys of the form "namespace/name".
+	allNamespaces := strings.Contains(after, "/")
+	if allNamespaces && !namespaced {
+		return fmt.Errorf("the token continues a list across namespaces, but %q is cluster-scoped", o.Resource)
+	}
+	ns := o.Namespace
+	if allNamespaces || !namespaced {
+		ns = ""
+	}
+
+	restClient, err := newRestClient(*o.RESTConfig, gvr.GroupVersion())
+	if err != nil {
+		return err
+	}
+
+	pager := o.newPager(restClient, gvr, ns)
+	pager.Limit = 1
+	pager.Continue = token
+	next, err := pager.Next(context.Background())
+	if err != nil {
+		if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+			return fmt.Errorf("the token has expired: %w", err)
+		}
+		return fmt.Errorf("the server rejected the token for %s: %w", gvr.Resource, err)
+	}
+
+	fmt.Fprintf(w, "Resource:\t%s %s\n", gvr.GroupResource(), gvr.GroupVersion())
+	if len(next.Rows) == 0 {
+		fmt.Fprintf(w, "Next Object:\t<none>\n")
+		return nil
+	}
+	if obj, err := rowObject(next.Rows[0]); err == nil {
+		name := obj.GetName()
+		if obj.GetNamespace() != "" {
+			name = obj.GetNamespace() + "/" + name
+		}
+		fmt.Fprintf(w, "Next Object:\t%s\n", name)
+	}
+
+	// The remaining counts are estimates, and are only reported by the server
+	// for lists without a label selector.
+	fresh := o.newPager(restClient, gvr, ns)
+	fresh.Limit = 1
+	first, err := fresh.Next(context.Background())
+	if err != nil || next.RemainingItemCount == nil || first.RemainingItemCount == nil {
+		return nil
+	}
+	total := *first.RemainingItemCount + 1
+	position := total - *next.RemainingItemCount
+	fmt.Fprintf(w, "Estimated Position:\t%d of ~%d\n", position, total)
+	return nil
+}
+
+// isNamespaced returns true if the resource is namespace-scoped.
+func (o *HeadOptions) isNamespaced(gvr schema.GroupVersionResource) (bool, error) {
+	// The mapping resolved for the resource argument may not have needed
+	// the Mapper's full discovery, so don't trigger it here.
+	if o.mapping != nil && o.mapping.Resource == gvr {
+		return o.mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+	}
+	gvk, err := o.Mapper.KindFor(gvr)
+	if err != nil {
+		return false, err
+	}
+	mapping, err := o.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, err
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
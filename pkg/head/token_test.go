@@ -0,0 +1,307 @@
+package head
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+func encodeToken(json string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(json))
+}
+
+func TestDecodeContinueToken(t *testing.T) {
+	testCases := []struct {
+		name          string
+		token         string
+		expected      *ContinueToken
+		expectedError string
+	}{
+		{
+			name:     "valid token",
+			token:    encodeToken(`{"v":"meta.k8s.io/v1","rv":12345,"start":"default/pod-b\u0000"}`),
+			expected: &ContinueToken{APIVersion: "meta.k8s.io/v1", ResourceVersion: 12345, StartKey: "default/pod-b\x00"},
+		},
+		{
+			name:          "not base64",
+			token:         "not a token!",
+			expectedError: "continue token is not valid base64",
+		},
+		{
+			name:          "unknown version",
+			token:         encodeToken(`{"v":"meta.k8s.io/v2","rv":1,"start":"a"}`),
+			expectedError: `continue token has unrecognized version "meta.k8s.io/v2"`,
+		},
+		{
+			name:          "missing resource version",
+			token:         encodeToken(`{"v":"meta.k8s.io/v1","start":"a"}`),
+			expectedError: "continue token has invalid resourceVersion 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := DecodeContinueToken(tc.token)
+			if tc.expectedError != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tc.expectedError) {
+					t.Errorf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *token != *tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, token)
+			}
+		})
+	}
+}
+
+func TestRun_StaleContinueToken(t *testing.T) {
+	// Continuations read from the token's snapshot; fresh lists are far ahead.
+	var probes []string
+	fakeRT := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		table := &metav1.Table{
+			TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+			ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+			Rows:              []metav1.TableRow{{Cells: []interface{}{"pod-b"}}},
+		}
+		table.ResourceVersion = "5000"
+		if req.URL.Query().Get("continue") == "" {
+			probes = append(probes, req.URL.RawQuery)
+		} else {
+			table.ResourceVersion = "100"
+			table.Continue = encodeToken(`{"v":"meta.k8s.io/v1","rv":100,"start":"default/pod-c\u0000"}`)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(mustMarshalJSON(table))),
+		}, nil
+	})
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		config.Transport = fakeRT
+		config.GroupVersion = &gv
+		config.APIPath = "/api"
+		config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+		return rest.RESTClientFor(&config)
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+	opts := &HeadOptions{
+		Resource:      "pods",
+		Limit:         1,
+		ContinueToken: encodeToken(`{"v":"meta.k8s.io/v1","rv":100,"start":"default/pod-b\u0000"}`),
+		RESTConfig:    &rest.Config{},
+		Mapper:        fakeRESTMapper(),
+		IOStreams:     streams,
+		PrintFlags:    genericclioptions.NewPrintFlags(""),
+	}
+
+	if err := opts.Run(); err != nil {
+		t.Fatalf("unexpected error during Run: %v", err)
+	}
+
+	// The probe reads a single item, without resourceVersion=0, whose reads
+	// from the watch cache may ignore the limit.
+	if len(probes) != 1 || !strings.Contains(probes[0], "limit=1") || strings.Contains(probes[0], "resourceVersion=") {
+		t.Errorf("expected a single limited probe request, got %v", probes)
+	}
+	if !strings.Contains(errOut.String(), "4900 revisions behind the current resourceVersion 5000") {
+		t.Errorf("expected a stale token warning, got %q", errOut.String())
+	}
+	if !strings.Contains(out.String(), "Resource Version: 100\nContinue Token: ") {
+		t.Errorf("expected the page's resource version before the token, got:\n%s", out.String())
+	}
+}
+
+func TestInspectToken(t *testing.T) {
+	remaining := func(n int64) *int64 { return &n }
+	// The continued list has 6 items left after the next one; the fresh list
+	// has 9 items after the first.
+	fakeRT := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		table := &metav1.Table{
+			TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+			ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+			Rows: []metav1.TableRow{{
+				Cells:  []interface{}{"pod-a"},
+				Object: rawPod("default", "pod-a"),
+			}},
+		}
+		table.RemainingItemCount = remaining(9)
+		if req.URL.Query().Get("continue") != "" {
+			table.Rows[0].Object = rawPod("team-b", "pod-c")
+			table.RemainingItemCount = remaining(6)
+		}
+		if req.URL.Path != "/api/v1/pods" {
+			t.Errorf("expected a list across all namespaces, got %s", req.URL.Path)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(mustMarshalJSON(table))),
+		}, nil
+	})
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		config.Transport = fakeRT
+		config.GroupVersion = &gv
+		config.APIPath = "/api"
+		config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+		return rest.RESTClientFor(&config)
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	token := encodeToken(`{"v":"meta.k8s.io/v1","rv":100,"start":"team-b/pod-b\u0000"}`)
+	testCases := []struct {
+		name          string
+		resource      string
+		clusterScoped bool
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:     "offline",
+			expected: []string{"Resource Version: 100", `Start Key: "team-b/pod-b\x00"`, "Resumes After: team-b/pod-b"},
+		},
+		{
+			name:     "against pods",
+			resource: "pods",
+			expected: []string{"Resource: pods v1", "Next Object: team-b/pod-c", "Estimated Position: 4 of ~10"},
+		},
+		{
+			name:          "against a cluster-scoped resource",
+			resource:      "pods",
+			clusterScoped: true,
+			expectedError: `the token continues a list across namespaces, but "pods" is cluster-scoped`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			mapper := fakeRESTMapper().(*fakeRESTMapperImpl)
+			mapper.clusterScoped = tc.clusterScoped
+			opts := &HeadOptions{
+				Resource:   tc.resource,
+				Namespace:  "default",
+				RESTConfig: &rest.Config{},
+				Mapper:     mapper,
+				IOStreams:  streams,
+			}
+
+			err := opts.InspectToken(token)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Errorf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Collapse the column padding so the expectations don't depend on it.
+			output := regexp.MustCompile(` +`).ReplaceAllString(out.String(), " ")
+			for _, want := range tc.expected {
+				if !strings.Contains(output, want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRun_PrintsResourceVersion(t *testing.T) {
+	// The list has two pages read from the snapshot at resourceVersion 100.
+	fakeRT := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		table := &metav1.Table{
+			TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+			ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}},
+			Rows: []metav1.TableRow{{
+				Cells:  []interface{}{"pod-a"},
+				Object: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-a"}}`)},
+			}},
+		}
+		table.ResourceVersion = "100"
+		if req.URL.Query().Get("continue") == "" && req.URL.Query().Get("limit") == "1" {
+			table.Continue = encodeToken(`{"v":"meta.k8s.io/v1","rv":100,"start":"default/pod-a\u0000"}`)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(mustMarshalJSON(table))),
+		}, nil
+	})
+	newRestClient = func(config rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+		config.Transport = fakeRT
+		config.GroupVersion = &gv
+		config.APIPath = "/api"
+		config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+		return rest.RESTClientFor(&config)
+	}
+	defer func() { newRestClient = NewRestClient }()
+
+	testCases := []struct {
+		name           string
+		limit          int64
+		all            bool
+		output         string
+		expectedOut    string
+		expectedErrOut string
+	}{
+		{
+			name:        "single page",
+			limit:       10,
+			expectedOut: "pod-a\n\nResource Version: 100\n",
+		},
+		{
+			name:           "single page with yaml output",
+			limit:          10,
+			output:         "yaml",
+			expectedErrOut: "\nResource Version: 100\n",
+		},
+		{
+			name:           "all pages",
+			limit:          1,
+			all:            true,
+			expectedErrOut: "\rFetched 1 items in 1 pages at resourceVersion 100\rFetched 2 items in 2 pages at resourceVersion 100\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+			opts := &HeadOptions{
+				Resource:   "pods",
+				Limit:      tc.limit,
+				All:        tc.all,
+				RESTConfig: &rest.Config{},
+				Mapper:     fakeRESTMapper(),
+				IOStreams:  streams,
+				PrintFlags: genericclioptions.NewPrintFlags("").WithDefaultOutput(tc.output),
+			}
+			if err := opts.Run(); err != nil {
+				t.Fatalf("unexpected error during Run: %v", err)
+			}
+
+			if !strings.HasSuffix(out.String(), tc.expectedOut) {
+				t.Errorf("expected output to end with %q, got %q", tc.expectedOut, out.String())
+			}
+			if !strings.HasSuffix(errOut.String(), tc.expectedErrOut) {
+				t.Errorf("expected stderr to end with %q, got %q", tc.expectedErrOut, errOut.String())
+			}
+		})
+	}
+}